@@ -1,27 +1,69 @@
 package main
 
 import (
+	"bytes"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/parser/ocr"
+	"git.home/c6bank-transactions/internal/report"
 )
 
 const (
 	ok            = "ok"
 	qifMIME       = "text/qif"
 	csvMIME       = "text/csv"
+	zipMIME       = "application/zip"
+	jsonMIME      = "application/json"
 	maxUploadSize = 32 << 20
+	ocrCacheSize  = 256
+	// maxReportFiles is how many files the report endpoints merge, two
+	// years of invoices
+	maxReportFiles   = 24
+	defaultTopPayees = 10
 )
 
+// ocrCache keeps recent screenshot OCR results, so re-uploading the same
+// image skips Tesseract.
+var ocrCache = ocr.NewLRU(ocrCacheSize)
+
+// parseWorkers is how many files of a single request are parsed at the same
+// time.
+var parseWorkers = 1
+
+// payees normalizes the payees of uploads sent with normalize_payees, with
+// the aliases loaded at startup.
+var payees = new(parser.Payees)
+
 //go:embed index.html
 var indexHTML []byte
 
+//go:embed report.html
+var reportHTML string
+
+// reportTemplate renders report.Spending as a page of tables.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cents": parser.FormatCents,
+	"month": func(t time.Time) string { return t.Format("01/2006") },
+	"totals": func(title, name string, totals []report.Total) any {
+		return struct {
+			Title, Name string
+			Totals      []report.Total
+		}{title, name, totals}
+	},
+}).Parse(reportHTML))
+
 func indexHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Add("Content-Type", "text/html")
 
@@ -30,6 +72,31 @@ func indexHandler(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// limiter bounds how many requests run the wrapped handlers at the same
+// time; the others wait for a free slot or give up when the client goes away.
+func limiter(n int) func(next http.HandlerFunc) http.HandlerFunc {
+	slots := make(chan struct{}, n)
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return limit(slots, next)
+	}
+}
+
+func limit(slots chan struct{}, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-r.Context().Done():
+			http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+
+			return
+		}
+
+		next(w, r)
+	}
+}
+
 func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -39,11 +106,47 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	if err := validate(r); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
 	}
 
 	number := r.PostFormValue("number")
 	includeProcessing := r.PostFormValue("include_processing") == "1"
 
+	opts := []parser.Option{parser.WithOCRCache(ocrCache)}
+	if r.FormValue("cost_splits") == "1" {
+		opts = append(opts, parser.WithCostSplits())
+	}
+
+	if r.FormValue("csv_columns") == "1" {
+		opts = append(opts, parser.WithCSVColumns())
+	}
+
+	if r.FormValue("normalize_payees") == "1" {
+		opts = append(opts, parser.WithPayees(payees))
+	}
+
+	filter, err := parser.ParseFilter(r.FormValue)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	split, err := parser.ParseSplitMode(r.FormValue("split"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	future, err := parser.ParseFutureMode(r.FormValue("scheduled"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
 	file, fileHeader, err := r.FormFile("file")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -59,7 +162,9 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	output, outputname, err := parser.Parse(filename, file, fileHeader.Size, number, includeProcessing)
+	opts = append(opts, parser.WithFilter(filter), parser.WithSplit(split), parser.WithFuture(future))
+
+	output, outputname, upload, err := parser.Parse(filename, file, fileHeader.Size, number, includeProcessing, opts...)
 	if err != nil {
 		fmt.Printf("ERROR file=%q: %s\n", filename, err)
 		http.Error(w, fmt.Sprintf("could not parse %s: %s", filename, err), http.StatusBadRequest)
@@ -67,11 +172,15 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	logWarnings(filename, upload.Skipped, upload.Discrepancies)
 	log.Printf("%s INFO received upload %s of type %s and parsed as %s\n", time.Now().Format(time.RFC3339), filename, filetype, outputname)
 
 	contentType := qifMIME
-	if strings.HasSuffix(outputname, ".csv") {
+	switch {
+	case strings.HasSuffix(outputname, ".csv"):
 		contentType = csvMIME
+	case strings.HasSuffix(outputname, ".zip"):
+		contentType = zipMIME
 	}
 
 	w.Header().Set("Content-Type", contentType)
@@ -85,18 +194,205 @@ func uploadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// installmentsHandler answers with the JSON schedule of the installments
+// still to be billed in the uploaded invoice.
+func installmentsHandler(w http.ResponseWriter, r *http.Request) {
+	transactions, ok := uploadedTransactions(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := report.Installments(transactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	writeJSON(w, schedule)
+}
+
+// subscriptionsHandler answers with the JSON list of the payees charged at
+// a regular cadence in the uploaded files.
+func subscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	transactions, ok := uploadedTransactions(w, r)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := report.Subscriptions(transactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	writeJSON(w, subscriptions)
+}
+
+// reportHandler answers with the spending summary of the uploaded files: a
+// page of tables, or JSON with format=json. top is how many payees are
+// listed.
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	transactions, ok := uploadedTransactions(w, r)
+	if !ok {
+		return
+	}
+
+	top := defaultTopPayees
+	if v := r.FormValue("top"); v != "" {
+		var err error
+		if top, err = strconv.Atoi(v); err != nil || top < 0 {
+			http.Error(w, fmt.Sprintf("invalid top %q", v), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	spending, err := report.Summarize(transactions, top)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if r.FormValue("format") == "json" {
+		writeJSON(w, spending)
+
+		return
+	}
+
+	var page bytes.Buffer
+	if err := reportTemplate.Execute(&page, spending); err != nil {
+		http.Error(w, fmt.Sprintf("could not write response: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	_, _ = page.WriteTo(w)
+}
+
+// uploadedTransactions parses the uploaded files, up to maxReportFiles
+// `file` params, writing the error response when it can't. Several files,
+// such as the invoices of a few months, are merged as the CLI does.
+func uploadedTransactions(w http.ResponseWriter, r *http.Request) ([]parser.Transaction, bool) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	if err := validateFiles(r, maxReportFiles); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return nil, false
+	}
+
+	files := r.MultipartForm.File["file"]
+
+	for _, fileHeader := range files {
+		if err := validateUploadHeader(fileHeader); err != nil {
+			http.Error(w, fmt.Sprintf("could not parse %s: %s", fileHeader.Filename, err), http.StatusBadRequest)
+
+			return nil, false
+		}
+	}
+
+	includeProcessing := r.PostFormValue("include_processing") == "1"
+
+	opts := []parser.Option{parser.WithOCRCache(ocrCache)}
+	if r.FormValue("normalize_payees") == "1" {
+		opts = append(opts, parser.WithPayees(payees))
+	}
+
+	var transactions []parser.Transaction
+
+	for _, result := range parser.ParseUploads(files, parseWorkers, r.PostFormValue("number"), includeProcessing, opts...) {
+		var skipped *parser.SkippedError
+
+		if errors.As(result.Err, &skipped) {
+			logWarnings(result.Path, skipped.Rows, nil)
+		} else if result.Err != nil {
+			fmt.Printf("ERROR file=%q: %s\n", result.Path, result.Err)
+			http.Error(w, fmt.Sprintf("could not parse %s: %s", result.Path, result.Err), http.StatusBadRequest)
+
+			return nil, false
+		}
+
+		logWarnings(result.Path, nil, result.Discrepancies)
+
+		transactions = append(transactions, result.Transactions...)
+	}
+
+	if len(files) > 1 {
+		transactions, _ = parser.Reconcile(transactions)
+		transactions, _ = parser.DefaultMatcher.Merge(parser.Deduplicate(transactions))
+		transactions, _ = parser.LinkInvoicePayments(transactions)
+		transactions, _ = parser.MatchRefunds(transactions)
+	}
+
+	return transactions, true
+}
+
+// validateUploadHeader checks the content of an uploaded file matches its
+// extension.
+func validateUploadHeader(fileHeader *multipart.FileHeader) error {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = validateUploadFile(fileHeader.Filename, file)
+
+	return err
+}
+
+// logWarnings warns about what could not be trusted in an uploaded file.
+func logWarnings(name string, skipped []parser.SkippedRow, discrepancies []parser.BalanceDiscrepancy) {
+	for _, row := range skipped {
+		log.Printf("%s WARNING file=%q: skipped row %d: %s\n", time.Now().Format(time.RFC3339), name, row.Row, row.Reason)
+	}
+
+	for _, d := range discrepancies {
+		log.Printf("%s WARNING file=%q: %s\n", time.Now().Format(time.RFC3339), name, d)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", jsonMIME)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("could not write response: %s", err), http.StatusInternalServerError)
+	}
+}
+
+// validate checks the request is a POST of a single `file` param.
 func validate(r *http.Request) error {
+	return validateFiles(r, 1)
+}
+
+// validateFiles checks the request is a POST of one to maxFiles `file`
+// params.
+func validateFiles(r *http.Request, maxFiles int) error {
 	if r.Method != "POST" {
 		return fmt.Errorf("method %q not allowed", r.Method)
 	}
 
-	files, ok := r.MultipartForm.File["file"]
-	if !ok || len(files) != 1 {
+	files := r.MultipartForm.File["file"]
+
+	switch {
+	case len(files) == 0 || (maxFiles == 1 && len(files) > 1):
 		return fmt.Errorf("%w: expected one `file` param", http.ErrMissingFile)
+	case len(files) > maxFiles:
+		return fmt.Errorf("%w: expected up to %d `file` params", http.ErrMissingFile, maxFiles)
 	}
 
-	if files[0].Size > MAX_UPLOAD_SIZE {
-		return fmt.Errorf("the uploaded image %q is too big. Please use an image less than 10MB in size", files[0].Filename)
+	for _, f := range files {
+		if f.Size > MAX_UPLOAD_SIZE {
+			return fmt.Errorf("the uploaded image %q is too big. Please use an image less than 10MB in size", f.Filename)
+		}
 	}
 
 	return nil
@@ -104,17 +400,19 @@ func validate(r *http.Request) error {
 
 func validateUploadFile(name string, file io.ReadSeeker) (string, error) {
 	buff := make([]byte, 512)
-	if _, err := file.Read(buff); err != nil {
+	n, err := file.Read(buff)
+	if err != nil {
 		return "", err
 	}
 
-	filetype := http.DetectContentType(buff)
+	// files smaller than the buffer would be sniffed as binary
+	filetype := http.DetectContentType(buff[:n])
 
 	if err := parser.IsValid(name, filetype); err != nil {
 		return "", err
 	}
 
-	_, err := file.Seek(0, io.SeekStart)
+	_, err = file.Seek(0, io.SeekStart)
 
 	return filetype, err
 }
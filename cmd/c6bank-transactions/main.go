@@ -6,7 +6,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
 	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
 )
 
 const MAX_UPLOAD_SIZE = 10 * 1024 * 1024 // 10MB
@@ -14,13 +18,21 @@ const MAX_UPLOAD_SIZE = 10 * 1024 * 1024 // 10MB
 func main() {
 	log.SetFlags(0)
 
+	payees = loadPayees()
+	parseWorkers = workers()
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", indexHandler)
 	mux.HandleFunc("/healthz", healthz)
-	mux.HandleFunc("/upload", uploadHandler)
+	// every endpoint parsing an upload shares the same workers
+	parsing := limiter(parseWorkers)
+	mux.HandleFunc("/upload", parsing(uploadHandler))
+	mux.HandleFunc("/installments", parsing(installmentsHandler))
+	mux.HandleFunc("/subscriptions", parsing(subscriptionsHandler))
+	mux.HandleFunc("/report", parsing(reportHandler))
 
 	host := getenv("HOST", "0.0.0.0")
 	port := getenv("PORT", "4500")
@@ -50,6 +62,33 @@ func main() {
 	}
 }
 
+// workers reads WORKERS, the number of uploads, and of files within one
+// upload, parsed at the same time,
+// defaulting to the number of CPUs since each screenshot runs Tesseract.
+func workers() int {
+	n, err := strconv.Atoi(getenv("WORKERS", strconv.Itoa(runtime.NumCPU())))
+	if err != nil || n < 1 {
+		log.Printf("ERROR invalid WORKERS, using %d", runtime.NumCPU())
+
+		return runtime.NumCPU()
+	}
+
+	return n
+}
+
+// loadPayees reads PAYEE_ALIASES, the merchant alias file used when uploads
+// ask for normalized payees.
+func loadPayees() *parser.Payees {
+	p, err := parser.LoadPayeeAliases(getenv("PAYEE_ALIASES", ""))
+	if err != nil {
+		log.Printf("ERROR invalid PAYEE_ALIASES, using none: %s", err)
+
+		return new(parser.Payees)
+	}
+
+	return p
+}
+
 func getenv(key, fallback string) string {
 	value := os.Getenv(key)
 	if len(value) == 0 {
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/parser/ocr"
+	"git.home/c6bank-transactions/internal/qif"
+)
+
+const (
+	formatText  = "text"
+	dateFormat  = "02/01/2006"
+	monthFormat = "01/2006"
+
+	accountBank = "bank"
+	accountCard = "ccard"
+)
+
+var filterUsage = map[string]string{
+	"from":         "only transactions on or after this date (YYYY-MM-DD)",
+	"to":           "only transactions on or before this date (YYYY-MM-DD)",
+	"card":         "only these card endings, comma separated",
+	"payee":        "only payees matching this regular expression (case insensitive)",
+	"min":          "only transactions of at least this absolute amount",
+	"max":          "only transactions of at most this absolute amount",
+	"installments": "only or exclude installment purchases",
+	"future":       "only or exclude projected future installments",
+	"sign":         "only debits or credits",
+}
+
+// common holds the flags shared by every command. Parsing flags are only
+// registered by commands that read transaction files.
+type common struct {
+	fs      *flag.FlagSet
+	formats []string
+
+	format  string
+	output  string
+	config  string
+	account string
+	filters map[string]*string
+
+	jobs       int
+	fuzzy      bool
+	fuzzyDays  int
+	reconcile  bool
+	transfers  bool
+	refunds    bool
+	netRefunds bool
+	top        int
+	noCache    bool
+	cacheDir   string
+	keepGoing  bool
+	split      string
+	costSplits bool
+	scheduled  string
+	csvColumns bool
+	prior      string
+	totals     []parser.InvoiceTotal
+	password   string
+	payees     bool
+	aliases    string
+
+	// balances are the statement balances read by load
+	balances []parser.Balance
+	// statement tells load read an account statement
+	statement bool
+}
+
+// newCommon creates the flag set of a command accepting the given output
+// formats, the first one being the default.
+func (c *cli) newCommon(name, args, summary string, formats []string) *common {
+	cm := &common{
+		fs:      flag.NewFlagSet(name, flag.ContinueOnError),
+		formats: formats,
+	}
+
+	cm.fs.SetOutput(c.stderr)
+	cm.fs.StringVar(&cm.format, "format", formats[0], "output format: "+strings.Join(formats, ", "))
+	cm.fs.StringVar(&cm.output, "o", "", "output file (defaults to stdout)")
+	cm.fs.StringVar(&cm.config, "config", defaultConfigPath(), "JSON file with default flag values, keyed by flag name")
+
+	cm.fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: %s %s [flags] %s\n", "cli", name, args)
+		fmt.Fprintln(c.stderr, summary)
+		fmt.Fprintln(c.stderr)
+		fmt.Fprintln(c.stderr, "Supported input formats: CSV, PDF account statements and card invoices, PNG, JPG/JPEG, and previous CSV, QIF or OFX exports")
+		fmt.Fprintln(c.stderr)
+		fmt.Fprintln(c.stderr, "Flags:")
+		cm.fs.PrintDefaults()
+	}
+
+	return cm
+}
+
+// parsingFlags registers the flags used to read and select transactions.
+func (cm *common) parsingFlags() *common {
+	cm.fs.StringVar(&cm.account, "account", "", "account type of the transactions: bank or ccard (default bank when reading an account statement, ccard otherwise)")
+
+	cm.filters = make(map[string]*string, len(parser.FilterKeys))
+	for _, key := range parser.FilterKeys {
+		cm.filters[key] = cm.fs.String(key, "", filterUsage[key])
+	}
+
+	cm.fs.IntVar(&cm.jobs, "j", runtime.NumCPU(), "number of files parsed in parallel")
+	cm.fs.BoolVar(&cm.payees, "normalize-payees", false, "clean up payees (acquirer prefixes, casing) and collapse merchant variants, keeping the raw payee in the memo")
+	cm.fs.StringVar(&cm.aliases, "aliases", defaultAliasesPath(), "file of merchant aliases used by -normalize-payees, as Name = VARIANT, PREFIX*")
+	cm.fs.StringVar(&cm.password, "password", "", "password of protected PDF statements and invoices")
+	cm.fs.BoolVar(&cm.noCache, "no-cache", false, "always run OCR, ignoring cached results")
+	cm.fs.StringVar(&cm.cacheDir, "cache-dir", defaultCacheDir(), "directory for cached OCR results")
+	cm.fs.BoolVar(&cm.keepGoing, "keep-going", false, fmt.Sprintf("output what could be parsed when some files fail, exiting with %d", exitPartial))
+	cm.fs.Func("total", fmt.Sprintf("invoice total to check the transactions against, as MM/YYYY=amount (repeatable); exits with %d when they don't add up", exitPartial), func(s string) error {
+		total, err := parser.ParseInvoiceTotal(s)
+		cm.totals = append(cm.totals, total)
+
+		return err
+	})
+
+	return cm
+}
+
+// matchingFlags registers the flags of commands merging several files, which
+// match transactions across files. enabled is the default of every match,
+// off where the output should stay as the files were.
+func (cm *common) matchingFlags(enabled bool) *common {
+	cm.fs.BoolVar(&cm.fuzzy, "fuzzy", enabled, "merge the same transaction read from different sources (screenshot, invoice, export)")
+	cm.fs.IntVar(&cm.fuzzyDays, "fuzzy-days", parser.DefaultMatcher.MaxDays, "how many days apart the same transaction can be dated in different sources")
+	cm.fs.BoolVar(&cm.reconcile, "reconcile", enabled, "replace projected installments with the ones billed by later invoices, dropping the ones never billed")
+	cm.fs.BoolVar(&cm.transfers, "transfers", enabled, "turn invoice payments from the statement and their credit in the invoice into transfers")
+	cm.fs.BoolVar(&cm.refunds, "refunds", enabled, "link card refunds to the purchase they give back")
+
+	return cm
+}
+
+// outputFlags registers the flags of commands writing transactions.
+func (cm *common) outputFlags() *common {
+	cm.fs.StringVar(&cm.split, "split", "", "split the output per card: files (one -o file per card) or accounts (QIF with one account per card)")
+	cm.fs.BoolVar(&cm.costSplits, "cost-splits", false, "break installment and international purchases into principal, IOF and fees (QIF splits)")
+	cm.fs.StringVar(&cm.scheduled, "scheduled", "", "projected future installments: exclude, mark (uncleared in QIF, Scheduled column in CSV, memo prefix in OFX and ledger) or separate (written to a -scheduled file next to -o)")
+	cm.fs.BoolVar(&cm.csvColumns, "csv-columns", false, "add the ID and Category columns to CSV output")
+
+	return cm
+}
+
+// parse parses args, applies the config file to flags not given on the
+// command line and validates the common flags.
+func (cm *common) parse(args []string) error {
+	if err := cm.fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := cm.applyConfig(); err != nil {
+		return err
+	}
+
+	if !slices.Contains(cm.formats, cm.format) {
+		return fmt.Errorf("%w: %q, use one of %s", parser.ErrUnknownFormat, cm.format, strings.Join(cm.formats, ", "))
+	}
+
+	if cm.account != "" && cm.account != accountBank && cm.account != accountCard {
+		return fmt.Errorf("invalid account type %q, use bank or ccard", cm.account)
+	}
+
+	if _, err := cm.filter(); err != nil {
+		return err
+	}
+
+	split, err := parser.ParseSplitMode(cm.split)
+
+	switch {
+	case err != nil:
+		return err
+	case split == parser.SplitFiles && cm.output == "":
+		return errors.New("-split files needs -o to name the output files")
+	case split == parser.SplitAccounts && cm.format != string(parser.FormatQIF):
+		return errors.New("-split accounts needs -format qif")
+	}
+
+	future, err := parser.ParseFutureMode(cm.scheduled)
+
+	switch {
+	case err != nil:
+		return err
+	case future == parser.FutureSeparate && cm.output == "":
+		return errors.New("-scheduled separate needs -o to name the output files")
+	}
+
+	return nil
+}
+
+// applyConfig sets flags from the config file. The default config file is
+// optional and keys for flags the command doesn't have are ignored.
+func (cm *common) applyConfig() error {
+	if cm.config == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(cm.config)
+	if errors.Is(err, fs.ErrNotExist) && cm.config == defaultConfigPath() {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("parse config %s: %w", cm.config, err)
+	}
+
+	set := make(map[string]bool)
+	cm.fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for name, value := range values {
+		if set[name] || cm.fs.Lookup(name) == nil {
+			continue
+		}
+
+		if err := cm.fs.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("config %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (cm *common) filter() (parser.Filter, error) {
+	if cm.filters == nil {
+		return parser.Filter{}, nil
+	}
+
+	return parser.ParseFilter(func(key string) string {
+		return *cm.filters[key]
+	})
+}
+
+// exportOptions are the options of parser.Render for the loaded files.
+func (cm *common) exportOptions(future parser.FutureMode) []parser.Option {
+	opts := []parser.Option{parser.WithFuture(future)}
+
+	if cm.csvColumns {
+		opts = append(opts, parser.WithCSVColumns())
+	}
+
+	if opening, closing, ok := parser.StatementBalances(cm.balances); ok {
+		opts = append(opts, parser.WithBalances(opening, closing))
+	}
+
+	return opts
+}
+
+// qifType is the account type given with -account or, without it, the one
+// of the loaded files: bank when one of them is an account statement.
+func (cm *common) qifType() qif.QIFType {
+	if cm.account == accountBank || (cm.account == "" && cm.statement) {
+		return qif.BankType
+	}
+
+	return qif.CreditCardType
+}
+
+// load parses every path, reporting progress on stderr, and returns the
+// filtered transactions sorted by date along with the exit code so far.
+func (cm *common) load(c *cli, paths []string, dedup bool) ([]parser.Transaction, int) {
+	var (
+		cache *ocr.DirCache
+		opts  = []parser.Option{parser.WithPassword(cm.password)}
+	)
+
+	if !cm.noCache && cm.cacheDir != "" {
+		cache = ocr.NewDirCache(cm.cacheDir)
+		opts = append(opts, parser.WithOCRCache(cache))
+	}
+
+	if cm.payees {
+		payees, err := parser.LoadPayeeAliases(cm.aliases)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+			return nil, exitError
+		}
+
+		opts = append(opts, parser.WithPayees(payees))
+	}
+
+	var (
+		all           []parser.Transaction
+		totals        []parser.InvoiceTotal
+		discrepancies []parser.BalanceDiscrepancy
+		reports       []fileReport
+		failed        bool
+	)
+
+	for i, result := range parser.ParseFiles(paths, cm.jobs, opts...) {
+		fmt.Fprintf(c.stderr, "[%d/%d] Parsing %s...\n", i+1, len(paths), filepath.Base(result.Path))
+
+		report := newFileReport(result)
+		reports = append(reports, report)
+
+		if result.Err != nil {
+			// skipped rows only warn, leaving the rest of the file in
+			if !report.usable() || (!cm.keepGoing && report.Status != statusPartial) {
+				fmt.Fprintf(c.stderr, "error: %v\n", result.Err)
+				failed = true
+				continue
+			}
+
+			fmt.Fprintf(c.stderr, "warning: %v\n", result.Err)
+
+			if !cm.keepGoing {
+				printSkipped(c.stderr, report)
+			}
+		}
+
+		fmt.Fprintf(c.stderr, "  found %d transaction(s)\n", len(result.Transactions))
+		all = append(all, result.Transactions...)
+
+		// a set of screenshots rarely holds every transaction of the invoice
+		// whose total they show, so only whole invoices are checked
+		if result.Source != parser.SourceScreenshot {
+			totals = append(totals, result.Totals...)
+		}
+
+		cm.balances = append(cm.balances, result.Balances...)
+		cm.statement = cm.statement || result.Source == parser.SourceStatement
+		discrepancies = append(discrepancies, result.Discrepancies...)
+
+		printDiscrepancies(c.stderr, result.Discrepancies)
+	}
+
+	if cm.keepGoing {
+		printReport(c.stderr, reports)
+	}
+
+	if failed && (!cm.keepGoing || !slices.ContainsFunc(reports, fileReport.usable)) {
+		return nil, exitError
+	}
+
+	if dedup {
+		var prior []parser.Transaction
+
+		if cm.prior != "" {
+			var err error
+			if prior, err = parser.ReadExport(cm.prior); err != nil {
+				fmt.Fprintf(c.stderr, "error: %v\n", err)
+				return nil, exitError
+			}
+		}
+
+		if cm.reconcile {
+			var rec parser.Reconciliation
+			all, rec = parser.Reconcile(all)
+			printReconciliation(c.stderr, rec)
+		}
+
+		fmt.Fprintf(c.stderr, "Deduplicating %d transaction(s)...\n", len(all))
+		all = parser.Deduplicate(all, prior)
+
+		if cm.fuzzy {
+			var merges []parser.Merge
+			all, merges = parser.Matcher{MaxDays: cm.fuzzyDays}.Merge(all)
+			printMerges(c.stderr, merges)
+		}
+
+		if cm.transfers {
+			var payments []parser.InvoicePayment
+			all, payments = parser.LinkInvoicePayments(all)
+			printPayments(c.stderr, payments)
+		}
+
+		if cm.refunds {
+			var refunds []parser.Refund
+			all, refunds = parser.MatchRefunds(all)
+			printRefunds(c.stderr, refunds)
+		}
+
+		fmt.Fprintf(c.stderr, "  %d unique transaction(s)\n", len(all))
+	}
+
+	// totals given on the command line win over the ones read from files
+	checks, err := parser.CheckTotals(all, append(totals, cm.totals...))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return nil, exitError
+	}
+
+	printTotals(c.stderr, checks)
+
+	if stats := cacheStats(cache); stats.Hits+stats.Misses > 0 {
+		fmt.Fprintf(c.stderr, "OCR cache: %d hit(s), %d miss(es)\n", stats.Hits, stats.Misses)
+	}
+
+	if cm.costSplits {
+		var err error
+		if all, err = parser.SplitCosts(all); err != nil {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+			return nil, exitError
+		}
+	}
+
+	filter, _ := cm.filter() // validated by parse
+	all = filter.Apply(all)
+
+	sortTransactions(all)
+
+	if (cm.keepGoing && slices.ContainsFunc(reports, func(r fileReport) bool { return r.Status != statusOK })) ||
+		slices.ContainsFunc(checks, func(c parser.TotalCheck) bool { return !c.OK() }) || len(discrepancies) > 0 {
+		return all, exitPartial
+	}
+
+	return all, exitOK
+}
+
+// write copies r to the -o file or stdout.
+func (cm *common) write(c *cli, r io.Reader) error {
+	return cm.writeTo(c, cm.output, r)
+}
+
+// writeTo copies r to the output file, or stdout when output is empty.
+func (cm *common) writeTo(c *cli, output string, r io.Reader) error {
+	var w io.Writer = c.stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+
+	return nil
+}
+
+func sortTransactions(transactions []parser.Transaction) {
+	slices.SortFunc(transactions, func(a, b parser.Transaction) int {
+		if a.Date.Before(b.Date) {
+			return -1
+		}
+		if a.Date.After(b.Date) {
+			return 1
+		}
+		return strings.Compare(a.Payee, b.Payee)
+	})
+}
+
+func formatNames(formats []parser.Format) []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, string(f))
+	}
+
+	return names
+}
+
+// defaultConfigPath is the optional config file read when -config is not given.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "c6bank-transactions", "config.json")
+}
+
+// defaultAliasesPath is the merchant alias file, optional like the config
+// file.
+func defaultAliasesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "c6bank-transactions", "aliases.txt")
+}
+
+// defaultCacheDir is where OCR results are kept between runs, or empty
+// (no cache) when the user cache directory is unknown.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "c6bank-transactions", "ocr")
+}
+
+func cacheStats(cache *ocr.DirCache) ocr.Stats {
+	if cache == nil {
+		return ocr.Stats{}
+	}
+
+	return cache.Stats()
+}
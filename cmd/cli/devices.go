@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"git.home/c6bank-transactions/internal/image"
+	"git.home/c6bank-transactions/internal/mobile"
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+func runDevices(c *cli, args []string) int {
+	cm := c.newCommon("devices", "[image ...]",
+		"List supported phone profiles, or detect the profile of each screenshot.",
+		[]string{formatText, string(parser.FormatCSV), string(parser.FormatJSON)})
+
+	if code, ok := c.parseFlags(cm, args); !ok {
+		return code
+	}
+
+	t := table{
+		Header: []string{"Name", "Width", "Height", "Header", "Footer", "Month", "MonthSize"},
+	}
+
+	phones := mobile.Phones
+	code := exitOK
+
+	if cm.fs.NArg() > 0 {
+		t.Header = append([]string{"File"}, t.Header...)
+		phones = nil
+
+		for _, path := range cm.fs.Args() {
+			phone, err := detectPhone(path)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "error: %s: %v\n", filepath.Base(path), err)
+				code = exitError
+			}
+
+			phones = append(phones, phone)
+		}
+	}
+
+	type device struct {
+		File string `json:"file,omitempty"`
+		mobile.Phone
+	}
+
+	var devices []device
+
+	for i, phone := range phones {
+		row := []string{
+			phone.Name, strconv.Itoa(phone.Width), strconv.Itoa(phone.Height),
+			strconv.Itoa(phone.Header), strconv.Itoa(phone.Footer),
+			strconv.Itoa(phone.Month), strconv.Itoa(phone.MonthSize),
+		}
+
+		d := device{Phone: phone}
+
+		if cm.fs.NArg() > 0 {
+			d.File = filepath.Base(cm.fs.Arg(i))
+			row = append([]string{d.File}, row...)
+		}
+
+		t.Rows = append(t.Rows, row)
+		devices = append(devices, d)
+	}
+
+	t.JSON = devices
+
+	r, err := renderTable(cm.format, t)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error generating %s: %v\n", cm.format, err)
+		return exitError
+	}
+
+	if err := cm.write(c, r); err != nil {
+		fmt.Fprintf(c.stderr, "error %v\n", err)
+		return exitError
+	}
+
+	return code
+}
+
+func detectPhone(path string) (mobile.Phone, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mobile.Phone{}, err
+	}
+	defer f.Close()
+
+	img, err := image.Decode(f)
+	if err != nil {
+		return mobile.Phone{}, err
+	}
+
+	phone, err := image.GetPhone(img)
+	if errors.Is(err, image.ErrUnsupportedPhone) {
+		bounds := img.Bounds().Max
+
+		return mobile.Phone{Width: bounds.X, Height: bounds.Y}, fmt.Errorf("%w: %dx%d", err, bounds.X, bounds.Y)
+	}
+
+	return phone, err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+func runDiff(c *cli, args []string) int {
+	cm := c.newCommon("diff", "<export> <export | file1 [file2 ...]>",
+		"List transactions added, removed or changed since a previous CSV, QIF or OFX export.",
+		[]string{formatText, string(parser.FormatCSV), string(parser.FormatJSON)}).parsingFlags().matchingFlags(false)
+
+	if code, ok := c.parseFlags(cm, args); !ok {
+		return code
+	}
+
+	if cm.fs.NArg() < 2 {
+		cm.fs.Usage()
+		return exitError
+	}
+
+	filter, _ := cm.filter()
+
+	old, err := parser.ReadExport(cm.fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	paths := cm.fs.Args()[1:]
+
+	var (
+		current []parser.Transaction
+		code    = exitOK
+	)
+
+	if len(paths) == 1 && parser.IsExport(paths[0]) {
+		current, err = parser.ReadExport(paths[0])
+		if err != nil {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+			return exitError
+		}
+	} else if current, code = cm.load(c, paths, true); code == exitError {
+		return code
+	}
+
+	result := parser.Diff(filter.Apply(old), filter.Apply(current))
+
+	fmt.Fprintf(c.stderr, "%d added, %d removed, %d changed\n", len(result.Added), len(result.Removed), len(result.Changed))
+
+	r, err := renderDiff(cm.format, result)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error generating %s: %v\n", cm.format, err)
+		return exitError
+	}
+
+	if err := cm.write(c, r); err != nil {
+		fmt.Fprintf(c.stderr, "error %v\n", err)
+		return exitError
+	}
+
+	return code
+}
+
+type diffRow struct {
+	Change   string `json:"change"`
+	Date     string `json:"date"`
+	Payee    string `json:"payee"`
+	Memo     string `json:"memo"`
+	Amount   string `json:"amount"`
+	Previous string `json:"previous,omitempty"`
+}
+
+func diffRows(result parser.DiffResult) []diffRow {
+	rows := make([]diffRow, 0, len(result.Added)+len(result.Removed)+len(result.Changed))
+
+	row := func(change string, t parser.Transaction, previous string) diffRow {
+		return diffRow{change, t.Date.Format(dateFormat), t.Payee, t.Memo, t.Amount, previous}
+	}
+
+	for _, t := range result.Added {
+		rows = append(rows, row(changeAdded, t, ""))
+	}
+
+	for _, t := range result.Removed {
+		rows = append(rows, row(changeRemoved, t, ""))
+	}
+
+	for _, ch := range result.Changed {
+		rows = append(rows, row(changeChanged, ch.New, ch.Old.Amount))
+	}
+
+	return rows
+}
+
+func renderDiff(format string, result parser.DiffResult) (io.Reader, error) {
+	rows := diffRows(result)
+
+	if format != formatText {
+		t := table{
+			Header: []string{"Change", "Date", "Payee", "Memo", "Value", "Previous"},
+			JSON:   rows,
+		}
+
+		for _, r := range rows {
+			t.Rows = append(t.Rows, []string{r.Change, r.Date, r.Payee, r.Memo, r.Amount, r.Previous})
+		}
+
+		return renderTable(format, t)
+	}
+
+	buf := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	marks := map[string]string{changeAdded: "+", changeRemoved: "-", changeChanged: "~"}
+
+	for _, r := range rows {
+		amount := r.Amount
+		if r.Previous != "" {
+			amount = r.Previous + " -> " + r.Amount
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", marks[r.Change], r.Date, r.Payee, r.Memo, amount)
+	}
+
+	return buf, tw.Flush()
+}
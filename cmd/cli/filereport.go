@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+const (
+	statusOK      = "ok"
+	statusPartial = "partial"
+	statusFailed  = "failed"
+)
+
+// fileReport summarizes what happened to one input file.
+type fileReport struct {
+	Path         string
+	Status       string
+	Transactions int
+	Skipped      []parser.SkippedRow
+	Err          error
+}
+
+func newFileReport(result parser.FileResult) fileReport {
+	report := fileReport{
+		Path:         result.Path,
+		Status:       statusOK,
+		Transactions: len(result.Transactions),
+		Err:          result.Err,
+	}
+
+	var skipped *parser.SkippedError
+
+	switch {
+	case errors.As(result.Err, &skipped):
+		report.Status = statusPartial
+		report.Skipped = skipped.Rows
+	case result.Err != nil:
+		report.Status = statusFailed
+	}
+
+	return report
+}
+
+// usable reports whether the file's transactions should be part of the output.
+func (r fileReport) usable() bool {
+	return r.Status != statusFailed
+}
+
+// printReport writes one row per file followed by every skipped row and why.
+func printReport(w io.Writer, reports []fileReport) {
+	fmt.Fprintln(w, "Report:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  FILE\tSTATUS\tTRANSACTIONS\tSKIPPED\tERROR")
+
+	for _, r := range reports {
+		var msg string
+		if r.Status == statusFailed {
+			msg = r.Err.Error()
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%d\t%s\n", filepath.Base(r.Path), r.Status, r.Transactions, len(r.Skipped), msg)
+	}
+
+	tw.Flush()
+
+	for _, r := range reports {
+		printSkipped(w, r)
+	}
+}
+
+// printSkipped writes every skipped row of the file and why.
+func printSkipped(w io.Writer, r fileReport) {
+	for _, row := range r.Skipped {
+		fmt.Fprintf(w, "  %s row %d skipped: %s (%s)\n",
+			filepath.Base(r.Path), row.Row, row.Reason, strings.Join(row.Line[:], ";"))
+	}
+}
+
+// printMerges lists the transactions merged across sources and why.
+func printMerges(w io.Writer, merges []parser.Merge) {
+	if len(merges) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "  merged %d transaction(s) found in more than one source:\n", len(merges))
+
+	for _, m := range merges {
+		fmt.Fprintf(w, "    %s %s %s from %s, dropped %q from %s: %s\n",
+			m.Kept.Date.Format(dateFormat), m.Kept.Payee, m.Kept.Amount, m.Kept.Source,
+			m.Dropped.Payee, m.Dropped.Source, strings.Join(m.Reasons, ", "))
+	}
+}
+
+func printReconciliation(w io.Writer, rec parser.Reconciliation) {
+	if len(rec.Realized) > 0 {
+		fmt.Fprintf(w, "Reconciled %d projected installment(s) with later invoices\n", len(rec.Realized))
+	}
+
+	for _, r := range rec.Realized {
+		if r.Difference != 0 {
+			fmt.Fprintf(w, "  %s %s %s billed %s, projected %s\n",
+				r.Billed.Date.Format(dateFormat), r.Billed.Payee, r.Billed.Memo, r.Billed.Amount, r.Projected.Amount)
+		}
+	}
+
+	if len(rec.Unbilled) > 0 {
+		fmt.Fprintf(w, "%d projected installment(s) never billed (cancelled or paid off early):\n", len(rec.Unbilled))
+	}
+
+	for _, t := range rec.Unbilled {
+		fmt.Fprintf(w, "  %s %s %s %s\n", t.Date.Format(dateFormat), t.Payee, t.Memo, t.Amount)
+	}
+}
+
+func printPayments(w io.Writer, payments []parser.InvoicePayment) {
+	if len(payments) > 0 {
+		fmt.Fprintf(w, "Linked %d invoice payment(s) with their credit in the invoice as transfers\n", len(payments))
+	}
+
+	for _, p := range payments {
+		fmt.Fprintf(w, "  %s %s %s -> %s %s\n",
+			p.Payment.Date.Format(dateFormat), p.Payment.Payee, p.Payment.Amount, p.Credit.Date.Format(dateFormat), p.Payment.Transfer)
+	}
+}
+
+func printRefunds(w io.Writer, refunds []parser.Refund) {
+	if len(refunds) > 0 {
+		fmt.Fprintf(w, "Matched %d refund(s) with their purchase\n", len(refunds))
+	}
+
+	for _, r := range refunds {
+		partial := ""
+		if r.Partial {
+			partial = " (partial)"
+		}
+
+		fmt.Fprintf(w, "  %s %s %s refunds %s %s %s%s\n",
+			r.Refund.Date.Format(dateFormat), r.Refund.Payee, r.Refund.Amount,
+			r.Purchase.Date.Format(dateFormat), r.Purchase.Payee, r.Purchase.Amount, partial)
+	}
+}
+
+func printTotals(w io.Writer, checks []parser.TotalCheck) {
+	for _, check := range checks {
+		month := check.Month.Format(monthFormat)
+
+		switch diff := check.Difference(); {
+		case diff == 0:
+			fmt.Fprintf(w, "Invoice %s: %d transaction(s) match the total of %s\n", month, check.Count, parser.FormatCents(check.Expected))
+			continue
+		case diff > 0:
+			fmt.Fprintf(w, "warning: invoice %s: %d transaction(s) add up to %s, %s more than the total of %s\n",
+				month, check.Count, parser.FormatCents(check.Parsed), parser.FormatCents(diff), parser.FormatCents(check.Expected))
+		default:
+			fmt.Fprintf(w, "warning: invoice %s: %d transaction(s) add up to %s, %s less than the total of %s; a row is probably missing\n",
+				month, check.Count, parser.FormatCents(check.Parsed), parser.FormatCents(-diff), parser.FormatCents(check.Expected))
+		}
+
+		if len(check.Extra) > 0 {
+			fmt.Fprintln(w, "  transactions of the extra amount, maybe read twice or from another invoice:")
+		}
+
+		for _, t := range check.Extra {
+			fmt.Fprintf(w, "    %s %s %s\n", t.Date.Format(dateFormat), t.Payee, t.Amount)
+		}
+	}
+}
+
+func printDiscrepancies(w io.Writer, discrepancies []parser.BalanceDiscrepancy) {
+	for _, d := range discrepancies {
+		fmt.Fprintf(w, "warning: %s; a row was probably skipped\n", d)
+	}
+}
@@ -1,94 +1,76 @@
 package main
 
 import (
-	"flag"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"slices"
-	"strings"
+)
 
-	"git.home/c6bank-transactions/internal/parser"
+// exitPartial means the output is missing files or rows (see -keep-going).
+const (
+	exitOK      = 0
+	exitError   = 1
+	exitPartial = 3
 )
 
+// command is a CLI subcommand, run with the arguments after its name.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+type cli struct {
+	stdout, stderr io.Writer
+}
+
+var commands = []command{
+	{"parse", "<file>", "Parse a single file into any output format.", runParse},
+	{"merge", "<file1> [file2 ...]", "Parse and merge files into a single deduplicated output.", runMerge},
+	{"diff", "<export> <export | file1 [file2 ...]>", "List transactions added, removed or changed since a previous export.", runDiff},
+	{"report", "<kind> <file1> [file2 ...]", "Summarize transactions.", runReport},
+	{"devices", "[image ...]", "List supported phone profiles, or detect the profile of screenshots.", runDevices},
+}
+
 func main() {
 	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
 }
 
 func run(args []string, stdout, stderr io.Writer) int {
-	fs := flag.NewFlagSet("cli", flag.ContinueOnError)
-	output := fs.String("o", "", "output CSV file (defaults to stdout)")
+	c := &cli{stdout: stdout, stderr: stderr}
 
-	fs.Usage = func() {
-		fmt.Fprintf(stderr, "Usage: %s [flags] <file1> [file2 ...]\n", "cli")
-		fmt.Fprintln(stderr, "Parse C6 Bank transaction files into a single CSV.")
-		fmt.Fprintln(stderr)
-		fmt.Fprintln(stderr, "Supported formats: CSV, PNG, JPG/JPEG")
-		fmt.Fprintln(stderr)
-		fmt.Fprintln(stderr, "Flags:")
-		fs.PrintDefaults()
+	if len(args) == 0 {
+		c.usage()
+		return exitError
 	}
 
-	if err := fs.Parse(args); err != nil {
-		fmt.Fprintf(stderr, "error: %v\n", err)
-		return 1
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		c.usage()
+		return exitOK
 	}
 
-	if fs.NArg() == 0 {
-		fs.Usage()
-		return 1
+	i := slices.IndexFunc(commands, func(cmd command) bool { return cmd.name == args[0] })
+	if i == -1 {
+		// files without a subcommand keep working as merge
+		return runMerge(c, args)
 	}
 
-	var all []parser.Transaction
-	paths := fs.Args()
-
-	for i, path := range paths {
-		fmt.Fprintf(stderr, "[%d/%d] Parsing %s...\n", i+1, len(paths), filepath.Base(path))
-		transactions, err := parser.ParseFile(path)
-		if err != nil {
-			fmt.Fprintf(stderr, "error: %v\n", err)
-			return 1
-		}
-		fmt.Fprintf(stderr, "  found %d transaction(s)\n", len(transactions))
-		all = append(all, transactions...)
-	}
-
-	fmt.Fprintf(stderr, "Deduplicating %d transaction(s)...\n", len(all))
-	all = parser.Deduplicate(all)
-	fmt.Fprintf(stderr, "  %d unique transaction(s)\n", len(all))
-
-	slices.SortFunc(all, func(a, b parser.Transaction) int {
-		if a.Date.Before(b.Date) {
-			return -1
-		}
-		if a.Date.After(b.Date) {
-			return 1
-		}
-		return strings.Compare(a.Payee, b.Payee)
-	})
-
-	r, err := parser.TransactionsToCSV(all)
-	if err != nil {
-		fmt.Fprintf(stderr, "error generating CSV: %v\n", err)
-		return 1
-	}
+	return commands[i].run(c, args[1:])
+}
 
-	var w io.Writer = stdout
-	if *output != "" {
-		f, err := os.Create(*output)
-		if err != nil {
-			fmt.Fprintf(stderr, "error creating output file: %v\n", err)
-			return 1
-		}
-		defer f.Close()
-		w = f
-	}
+func (c *cli) usage() {
+	fmt.Fprintf(c.stderr, "Usage: %s <command> [flags] <args>\n", "cli")
+	fmt.Fprintln(c.stderr, "Parse C6 Bank transaction files.")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Commands:")
 
-	if _, err := io.Copy(w, r); err != nil {
-		fmt.Fprintf(stderr, "error writing output: %v\n", err)
-		return 1
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", cmd.name, cmd.summary)
 	}
 
-	return 0
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Without a command, files are merged. Run `cli <command> -h` for its flags.")
 }
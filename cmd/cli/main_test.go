@@ -2,12 +2,20 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"git.home/c6bank-transactions/internal/image"
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/parser/ocr"
+	"git.home/c6bank-transactions/internal/qif"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +75,222 @@ func TestRun(t *testing.T) {
 			wantCount:  4,               // same file twice, deduplicated back to 4 transactions
 			wantExact:  "MERCADO EXTRA", // must appear exactly once
 		},
+		{
+			name:       "without OCR cache",
+			args:       []string{"--no-cache", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "MERCADO EXTRA",
+		},
+		{
+			name: "parallel parsing reports every failing file",
+			args: []string{
+				"-j", "2",
+				"nonexistent.csv",
+				filepath.Join(testdata, "dummy.xlsx"),
+			},
+			wantCode: 1,
+			wantErr:  "unsupported file format",
+		},
+		{
+			name: "keep going outputs partial results",
+			args: []string{
+				"--keep-going",
+				filepath.Join(testdata, "Fatura_2026-01-15.csv"),
+				filepath.Join(testdata, "dummy.xlsx"),
+			},
+			wantCode:   exitPartial,
+			wantErr:    "failed  0",
+			wantOutHas: "MERCADO EXTRA",
+		},
+		{
+			name:     "help lists commands",
+			args:     []string{"help"},
+			wantCode: exitOK,
+			wantErr:  "Commands:",
+		},
+		{
+			name:       "merge subcommand",
+			args:       []string{"merge", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "MERCADO EXTRA",
+			wantCount:  4,
+		},
+		{
+			name:     "parse has no matching flags",
+			args:     []string{"parse", "-fuzzy=false", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: exitError,
+			wantErr:  "flag provided but not defined: -fuzzy",
+		},
+		{
+			name:     "report has no output flags",
+			args:     []string{"report", "summary", "-split", "files", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: exitError,
+			wantErr:  "flag provided but not defined: -split",
+		},
+		{
+			name:       "parse into QIF",
+			args:       []string{"parse", "-format", "qif", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "!Type:CCard",
+		},
+		{
+			name:       "parse bank account into OFX",
+			args:       []string{"parse", "-format", "ofx", "-account", "bank", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "<TRNAMT>-167.91",
+		},
+		{
+			name:       "parse into JSON",
+			args:       []string{"parse", "-format", "json", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: `"payee": "MERCADO EXTRA"`,
+		},
+		{
+			name:     "parse takes a single file",
+			args:     []string{"parse", "a.csv", "b.csv"},
+			wantCode: 1,
+			wantErr:  "Usage: cli parse",
+		},
+		{
+			name:     "unknown output format",
+			args:     []string{"merge", "-format", "xlsx", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "unknown output format",
+		},
+		{
+			name:       "date filter",
+			args:       []string{"merge", "-from", "2026-01-02", "-to", "2026-02-28", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "05/02/2026",
+			wantCount:  2,
+		},
+		{
+			name:       "card and installment filters",
+			args:       []string{"merge", "-card", "5678", "-future", "exclude", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "05/01/2026,AMAZON BR",
+			wantCount:  1,
+		},
+		{
+			name:     "invalid date filter",
+			args:     []string{"merge", "-from", "01/01/2026", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "invalid filter: from",
+		},
+		{
+			name:       "report summary",
+			args:       []string{"report", "summary", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "01/2026  2             -217.91  0.00     -217.91",
+		},
+		{
+			name:       "report installments",
+			args:       []string{"report", "installments", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "AMAZON BR  5678  50.00        2/3        100.00  03/2026",
+		},
+		{
+			name:       "report payees",
+			args:       []string{"report", "payees", "-top", "1", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "Payee          Transactions  Debits   Credits  Net      \nMERCADO EXTRA  1             -167.91  0.00     -167.91  \n",
+		},
+		{
+			name:       "report categories",
+			args:       []string{"report", "categories", "-format", "csv", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "Category,Transactions,Debits,Credits,Net\nCompras,2,-217.91,0.00,-217.91\n",
+		},
+		{
+			name:     "report requires a kind",
+			args:     []string{"report", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "installments",
+		},
+		{
+			name:       "devices lists phone profiles",
+			args:       []string{"devices", "-format", "csv"},
+			wantCode:   0,
+			wantOutHas: "iPhone Mirror,836,1840,600,180,500,100",
+		},
+		{
+			name:       "devices detects screenshots",
+			args:       []string{"devices", filepath.Join(testdata, "..", "..", "..", "test", "fixtures", "IMG_0420.jpg")},
+			wantCode:   0,
+			wantOutHas: "IMG_0420.jpg  iPhone 16 Pro",
+		},
+		{
+			name:       "split accounts into a multi-account QIF",
+			args:       []string{"merge", "-format", "qif", "-split", "accounts", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "!Account\nNC6 5678 DANILO\nTCCard\nDCartão C6 final 5678 (DANILO)\n^\n!Type:CCard",
+		},
+		{
+			name:     "split accounts needs QIF",
+			args:     []string{"merge", "-split", "accounts", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "-split accounts needs -format qif",
+		},
+		{
+			name:     "split files needs an output file",
+			args:     []string{"merge", "-split", "files", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "-split files needs -o",
+		},
+		{
+			name:      "exclude scheduled installments",
+			args:      []string{"merge", "-scheduled", "exclude", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:  0,
+			wantCount: 2,
+		},
+		{
+			name:       "mark scheduled installments",
+			args:       []string{"merge", "-scheduled", "mark", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "Date,Payee,Memo,Value,Scheduled\n",
+		},
+		{
+			name:       "CSV columns",
+			args:       []string{"merge", "-csv-columns", "-scheduled", "mark", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode:   0,
+			wantOutHas: "Date,Payee,Memo,Value,ID,Scheduled,Category\n",
+		},
+		{
+			name:     "separate scheduled installments needs an output file",
+			args:     []string{"merge", "-scheduled", "separate", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "-scheduled separate needs -o",
+		},
+		{
+			name:     "invalid scheduled mode",
+			args:     []string{"merge", "-scheduled", "hide", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "invalid future mode",
+		},
+		{
+			name:     "invoice total matches",
+			args:     []string{"merge", "-total", "01/2026=217,91", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 0,
+			wantErr:  "Invoice 01/2026: 2 transaction(s) match the total of 217.91",
+		},
+		{
+			name:     "invoice total with a missing row",
+			args:     []string{"merge", "-total", "01/2026=300,00", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: exitPartial,
+			wantErr:  "82.09 less than the total of 300.00; a row is probably missing",
+		},
+		{
+			name:     "invalid invoice total",
+			args:     []string{"merge", "-total", "300,00", filepath.Join(testdata, "Fatura_2026-01-15.csv")},
+			wantCode: 1,
+			wantErr:  "invalid invoice total",
+		},
+		{
+			name:     "keep going without any usable file",
+			args:     []string{"--keep-going", "nonexistent.csv"},
+			wantCode: exitError,
+			wantErr:  "Report:",
+		},
 	}
 
 	for _, tt := range tests {
@@ -113,3 +337,538 @@ func TestRun_OutputToFile(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "MERCADO EXTRA")
 }
+
+func TestRun_SplitFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-split", "files", "-o", filepath.Join(dir, "fatura.csv"), filepath.Join(testdata, "Fatura_2026-01-15.csv")}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), "Wrote 3 transaction(s)")
+
+	data, err := os.ReadFile(filepath.Join(dir, "fatura-1234.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "MERCADO EXTRA")
+	assert.NotContains(t, string(data), "AMAZON BR")
+
+	data, err = os.ReadFile(filepath.Join(dir, "fatura-5678.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "AMAZON BR")
+}
+
+func TestRun_SplitAccounts(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"parse", "-format", "qif", "-split", "accounts", "-scheduled", "exclude", filepath.Join(testdata, "Fatura_2026-01-15.csv")}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "NC6 5678 DANILO\n")
+	assert.Contains(t, stdout.String(), "1/3 5678")
+	assert.NotContains(t, stdout.String(), "2/3 5678", "the scheduled mode applies to every account")
+}
+
+func TestRun_ScheduledSeparate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"-scheduled", "separate", "-o", filepath.Join(dir, "fatura.csv"), filepath.Join(testdata, "Fatura_2026-01-15.csv")}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), "Wrote 2 scheduled transaction(s)")
+
+	data, err := os.ReadFile(filepath.Join(dir, "fatura.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "1/3 5678")
+	assert.NotContains(t, string(data), "2/3 5678")
+
+	data, err = os.ReadFile(filepath.Join(dir, "fatura-scheduled.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "2/3 5678")
+	assert.Contains(t, string(data), "3/3 5678")
+}
+
+func TestRun_Prior(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	export := filepath.Join(dir, "export.qif")
+	invoice := filepath.Join(testdata, "Fatura_2026-01-15.csv")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"merge", "-format", "qif", "-o", export, invoice}, &stdout, &stderr), "stderr: %s", stderr.String())
+
+	t.Run("export as input", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", export, invoice}, &stdout, &stderr)
+
+		assert.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "  4 unique transaction(s)")
+	})
+
+	t.Run("suppresses the prior export", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", "-prior", export, invoice}, &stdout, &stderr)
+
+		assert.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "  0 unique transaction(s)")
+		assert.Equal(t, "Date,Payee,Memo,Value\n", stdout.String())
+	})
+
+	t.Run("diff against a QIF export", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"diff", export, invoice}, &stdout, &stderr)
+
+		assert.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "0 added, 0 removed, 0 changed")
+	})
+}
+
+func TestRun_Fuzzy(t *testing.T) {
+	t.Parallel()
+
+	export := filepath.Join(t.TempDir(), "export.csv")
+	content := "Date,Payee,Memo,Value\n02/01/2026,MERCAD0 EXTRA,,\"-167,91\"\n"
+	require.NoError(t, os.WriteFile(export, []byte(content), 0o600))
+
+	invoice := filepath.Join(testdata, "Fatura_2026-01-15.csv")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "-fuzzy", export, invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), `01/01/2026 MERCADO EXTRA -167,91 from invoice, dropped "MERCAD0 EXTRA" from export: same amount, payee matches with OCR confusions, dates 1 day(s) apart`)
+	assert.Contains(t, stderr.String(), "  4 unique transaction(s)")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// off by default, so files merged without a command come out as before
+	code = run([]string{export, invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.NotContains(t, stderr.String(), "dropped")
+	assert.Contains(t, stderr.String(), "  5 unique transaction(s)")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// reports count each purchase once
+	code = run([]string{"report", "installments", export, invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), "  4 unique transaction(s)")
+}
+
+func TestRun_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	header := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n"
+	january := filepath.Join(testdata, "Fatura_2026-01-15.csv")
+
+	t.Run("billed by the next invoice", func(t *testing.T) {
+		t.Parallel()
+
+		february := filepath.Join(t.TempDir(), "Fatura_2026-02-15.csv")
+		content := header + "05/01/2026;DANILO;5678;Compras;AMAZON BR;2/3;;;50,01\n"
+		require.NoError(t, os.WriteFile(february, []byte(content), 0o600))
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", "-reconcile", january, february}, &stdout, &stderr)
+
+		require.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "Reconciled 1 projected installment(s) with later invoices\n"+
+			"  05/02/2026 AMAZON BR 2/3 5678 02/2026 billed -50,01, projected -50,00\n")
+		assert.Contains(t, stdout.String(), "2/3 5678 02/2026,\"-50,01\"")
+		assert.NotContains(t, stdout.String(), "2/3 5678 02/2026,\"-50,00\"")
+
+		stdout.Reset()
+		stderr.Reset()
+
+		// off by default, so files merged without a command come out as before
+		code = run([]string{january, february}, &stdout, &stderr)
+
+		require.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.NotContains(t, stderr.String(), "Reconciled")
+		assert.Contains(t, stdout.String(), "2/3 5678 02/2026,\"-50,01\"")
+		assert.Contains(t, stdout.String(), "2/3 5678 02/2026,\"-50,00\"")
+	})
+
+	t.Run("never billed", func(t *testing.T) {
+		t.Parallel()
+
+		february := filepath.Join(t.TempDir(), "Fatura_2026-02-15.csv")
+		content := header + "10/02/2026;DANILO;1234;Compras;PADARIA;Única;;;9,00\n"
+		require.NoError(t, os.WriteFile(february, []byte(content), 0o600))
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", "-reconcile", january, february}, &stdout, &stderr)
+
+		require.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "2 projected installment(s) never billed (cancelled or paid off early):\n"+
+			"  05/02/2026 AMAZON BR 2/3 5678 02/2026 -50,00\n"+
+			"  05/03/2026 AMAZON BR 3/3 5678 03/2026 -50,00\n")
+		assert.Contains(t, stderr.String(), "  3 unique transaction(s)")
+	})
+}
+
+func TestRun_Refunds(t *testing.T) {
+	t.Parallel()
+
+	invoice := filepath.Join(t.TempDir(), "Fatura_2026-03-15.csv")
+	content := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n" +
+		"02/03/2026;DANILO;1234;Compras;LOJA X;Única;;;100,00\n" +
+		"05/03/2026;DANILO;1234;Alimentação;PADARIA;Única;;;20,00\n" +
+		"10/03/2026;DANILO;1234;Compras;ESTORNO LOJA X;Única;;;-100,00\n"
+	require.NoError(t, os.WriteFile(invoice, []byte(content), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "-refunds", "-format", "json", invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), "Matched 1 refund(s) with their purchase\n"+
+		"  10/03/2026 ESTORNO LOJA X 100,00 refunds 02/03/2026 LOJA X -100,00\n")
+	assert.Contains(t, stdout.String(), `"refund_of"`)
+
+	stdout.Reset()
+	stderr.Reset()
+
+	// off by default, so files merged without a command come out as before
+	code = run([]string{"-format", "json", invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.NotContains(t, stdout.String(), `"refund_of"`)
+
+	stdout.Reset()
+	stderr.Reset()
+
+	code = run([]string{"report", "summary", "-net-refunds", invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "03/2026  1             -20.00  0.00     -20.00")
+}
+
+func TestRun_NormalizePayees(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	invoice := filepath.Join(dir, "Fatura_2026-03-15.csv")
+	content := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n" +
+		"02/03/2026;DANILO;1234;Alimentação;IFD*REST X CENTRO;Única;;;45,00\n" +
+		"05/03/2026;DANILO;1234;Compras;AMAZON BR;Única;;;20,00\n"
+	require.NoError(t, os.WriteFile(invoice, []byte(content), 0o600))
+
+	aliases := filepath.Join(dir, "aliases.txt")
+	require.NoError(t, os.WriteFile(aliases, []byte("Restaurante X = REST X*\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"parse", "-format", "qif", "-normalize-payees", "-aliases", aliases, invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "PRestaurante X\n")
+	assert.Contains(t, stdout.String(), "IFD*REST X CENTRO 03/2026\n")
+	assert.Contains(t, stdout.String(), "PAmazon BR\n")
+}
+
+func TestRun_Subscriptions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	header := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n"
+
+	var paths []string
+
+	for i, amount := range []string{"39,90", "39,90", "44,90"} {
+		path := filepath.Join(dir, fmt.Sprintf("Fatura_2026-0%d-15.csv", i+1))
+		content := header + fmt.Sprintf("05/0%d/2026;DANILO;1234;Serviços;NETFLIX.COM;Única;;;%s\n", i+1, amount) +
+			fmt.Sprintf("%02d/0%d/2026;DANILO;1234;Alimentação;PADARIA;Única;;;1%d,00\n", i*9+2, i+1, i)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		paths = append(paths, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"report", "subscriptions"}, paths...), &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Equal(t, "Payee        Card  Cadence  Amount  Charges  First       Last        Next        Status  \n"+
+		"NETFLIX.COM  1234  monthly  44.90   3        05/01/2026  05/03/2026  05/04/2026  active  \n"+
+		"\n"+
+		"Payee        Date        From   To     \n"+
+		"NETFLIX.COM  05/03/2026  39.90  44.90  \n", stdout.String())
+}
+
+func TestRun_Subscriptions_Screenshots(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	today := time.Now()
+
+	var paths []string
+
+	// a weekly charge seen in three screenshots, read from the OCR cache
+	for i, name := range []string{"IMG_0420.PNG", "IMG_0426.PNG", "IMG_457634FF7133.png"} {
+		path := filepath.Join(testdata, name)
+		date := today.AddDate(0, 0, 7*(i-2))
+		text := fmt.Sprintf("%02d/%02d\n\nJORNAL R$ 19,90\nCartao final 1234\n", date.Day(), date.Month())
+
+		cacheScreenshot(t, cacheDir, path, text, "Fatura de janeiro Aberta")
+		paths = append(paths, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"report", "subscriptions", "-cache-dir", cacheDir}, paths...), &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "JORNAL  1234  weekly   19.90   3 ")
+	assert.Contains(t, stderr.String(), "OCR cache: 6 hit(s), 0 miss(es)")
+}
+
+func TestRun_ScreenshotTotals(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	path := filepath.Join(testdata, "IMG_0420.PNG")
+
+	// one screenshot of an invoice with more transactions than it shows
+	cacheScreenshot(t, cacheDir, path, "05/01\n\nJORNAL R$ 19,90\nCartao final 1234\n", "Fatura de janeiro Aberta R$ 500,00")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "-cache-dir", cacheDir, path}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.NotContains(t, stderr.String(), "invoice")
+	assert.Contains(t, stdout.String(), "JORNAL")
+}
+
+// cacheScreenshot stores in dir the OCR output of the screenshot at path:
+// text for its transactions and month for its month region.
+func cacheScreenshot(t *testing.T, dir, path, text, month string) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	cropped, reference, err := image.Crop(f)
+	require.NoError(t, err)
+
+	cache := ocr.NewDirCache(dir)
+
+	for r, output := range map[io.Reader]string{cropped: text, reference: month} {
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		cache.Put(ocr.Key(b), []byte(output))
+	}
+}
+
+func TestRun_CostSplits(t *testing.T) {
+	t.Parallel()
+
+	invoice := filepath.Join(t.TempDir(), "Fatura_2026-03-15.csv")
+	content := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n" +
+		"01/03/2026;DANILO;1234;Jogos;STEAM;Única;10,00;5,50;55,00\n" +
+		"01/03/2026;DANILO;1234;;IOF COMPRA INTERNACIONAL;Única;;;1,96\n"
+	require.NoError(t, os.WriteFile(invoice, []byte(content), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"parse", "-format", "qif", "-cost-splits", invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "PSTEAM\nT-56,96\n")
+	assert.Contains(t, stdout.String(), "SJogos\nEUS$ 10,00 x 5,50\n$-55,00\nSIOF\nEIOF COMPRA INTERNACIONAL\n$-1,96\n^")
+	assert.NotContains(t, stdout.String(), "PIOF")
+}
+
+func TestNewFileReport(t *testing.T) {
+	t.Parallel()
+
+	skipped := &parser.SkippedError{
+		Path: "Fatura_2026-01-15.csv",
+		Rows: []parser.SkippedRow{{Row: 2, Line: parser.Line{"", "PAYEE", "memo", "1,00"}, Reason: `invalid date ""`}},
+	}
+
+	tests := []struct {
+		name   string
+		result parser.FileResult
+		status string
+	}{
+		{"ok", parser.FileResult{Path: "a.csv", Transactions: make([]parser.Transaction, 2)}, statusOK},
+		{"skipped rows", parser.FileResult{Path: "b.csv", Transactions: make([]parser.Transaction, 1), Err: skipped}, statusPartial},
+		{"failed", parser.FileResult{Path: "c.csv", Err: errors.New("boom")}, statusFailed},
+	}
+
+	var reports []fileReport
+
+	for _, tt := range tests {
+		report := newFileReport(tt.result)
+		assert.Equal(t, tt.status, report.Status, tt.name)
+		reports = append(reports, report)
+	}
+
+	var out bytes.Buffer
+	printReport(&out, reports)
+
+	assert.Contains(t, out.String(), "a.csv  ok       2             0")
+	assert.Contains(t, out.String(), "c.csv  failed   0             0        boom")
+	assert.Contains(t, out.String(), `b.csv row 2 skipped: invalid date "" (;PAYEE;memo;1,00)`)
+}
+
+func TestQIFType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		account   string
+		statement bool
+		want      qif.QIFType
+	}{
+		{"invoice", "", false, qif.CreditCardType},
+		{"account statement", "", true, qif.BankType},
+		{"flag over the files", accountCard, true, qif.CreditCardType},
+		{"flag alone", accountBank, false, qif.BankType},
+	}
+
+	for _, tt := range tests {
+		cm := &common{account: tt.account, statement: tt.statement}
+		assert.Equal(t, tt.want, cm.qifType(), tt.name)
+	}
+}
+
+func TestRun_SkippedRows(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile(filepath.Join(testdata, "Fatura_2026-01-15.csv"))
+	require.NoError(t, err)
+
+	invoice := filepath.Join(t.TempDir(), "Fatura_2026-01-15.csv")
+	require.NoError(t, os.WriteFile(invoice, append(content, ";;;;;;;;\n"...), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "-format", "qif", invoice}, &stdout, &stderr)
+
+	// a blank row is ignored as it always was
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.NotContains(t, stderr.String(), "skipped")
+	assert.Contains(t, stdout.String(), "MERCADO EXTRA")
+
+	require.NoError(t, os.WriteFile(invoice, append(content, "32/01/2026;DANILO;1234;Compras;LOJA;Única;;;30,00\n"...), 0o600))
+
+	stdout.Reset()
+	stderr.Reset()
+
+	code = run([]string{"merge", "-format", "qif", invoice}, &stdout, &stderr)
+
+	// a row that can't be read only warns, keeping the rest of the invoice
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stderr.String(), "warning: skipped 1 unreadable transaction(s)")
+	assert.Contains(t, stderr.String(), `Fatura_2026-01-15.csv row 3 skipped: invalid date "32/01/2026"`)
+	assert.Contains(t, stdout.String(), "MERCADO EXTRA")
+
+	stdout.Reset()
+	stderr.Reset()
+
+	code = run([]string{"merge", "--keep-going", "-format", "qif", invoice}, &stdout, &stderr)
+
+	assert.Equal(t, exitPartial, code, "stderr: %s", stderr.String())
+}
+
+func TestRun_Diff(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	export := filepath.Join(dir, "export.csv")
+	invoice := filepath.Join(testdata, "Fatura_2026-01-15.csv")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, run([]string{"merge", "-o", export, invoice}, &stdout, &stderr), "stderr: %s", stderr.String())
+
+	t.Run("against the same file", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"diff", export, invoice}, &stdout, &stderr)
+
+		assert.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "0 added, 0 removed, 0 changed")
+		assert.Empty(t, stdout.String())
+	})
+
+	t.Run("against another export", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := os.ReadFile(export)
+		require.NoError(t, err)
+
+		edited := strings.Replace(string(data), `"-167,91"`, `"-170,00"`, 1)
+		edited = strings.Replace(edited, "AMAZON BR,3/3", "AMAZON,3/3", 1)
+
+		other := filepath.Join(t.TempDir(), "other.csv")
+		require.NoError(t, os.WriteFile(other, []byte(edited), 0o600))
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"diff", export, other}, &stdout, &stderr)
+
+		assert.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stderr.String(), "1 added, 1 removed, 1 changed")
+		assert.Contains(t, stdout.String(), "+  05/03/2026  AMAZON ")
+		assert.Contains(t, stdout.String(), "-  05/03/2026  AMAZON BR")
+		assert.Contains(t, stdout.String(), "-167,91 -> -170,00")
+	})
+
+	t.Run("not an export", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"diff", invoice, export}, &stdout, &stderr)
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "not a CSV exported by this tool")
+	})
+}
+
+func TestRun_Config(t *testing.T) {
+	t.Parallel()
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(config, []byte(`{"format": "qif", "account": "bank", "unknown": 1}`), 0o600))
+
+	invoice := filepath.Join(testdata, "Fatura_2026-01-15.csv")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "-config", config, invoice}, &stdout, &stderr)
+
+	require.Equal(t, 0, code, "stderr: %s", stderr.String())
+	assert.Contains(t, stdout.String(), "!Type:Bank")
+
+	t.Run("command line wins", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", "-config", config, "-format", "csv", invoice}, &stdout, &stderr)
+
+		require.Equal(t, 0, code, "stderr: %s", stderr.String())
+		assert.Contains(t, stdout.String(), "Date,Payee,Memo,Value")
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		t.Parallel()
+
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"merge", "-config", "missing.json", invoice}, &stdout, &stderr)
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "read config")
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+func runMerge(c *cli, args []string) int {
+	cm := c.newCommon("merge", "<file1> [file2 ...]",
+		"Parse C6 Bank transaction files into a single deduplicated output.",
+		formatNames(parser.Formats)).parsingFlags().matchingFlags(false).outputFlags()
+
+	cm.fs.StringVar(&cm.prior, "prior", "", "previous CSV, QIF or OFX export; transactions already in it are left out")
+
+	if code, ok := c.parseFlags(cm, args); !ok {
+		return code
+	}
+
+	if cm.fs.NArg() == 0 {
+		cm.fs.Usage()
+		return exitError
+	}
+
+	transactions, code := cm.load(c, cm.fs.Args(), true)
+	if code == exitError {
+		return code
+	}
+
+	return c.export(cm, transactions, code)
+}
+
+func runParse(c *cli, args []string) int {
+	cm := c.newCommon("parse", "<file>",
+		"Parse a single C6 Bank transaction file into any output format.",
+		formatNames(parser.Formats)).parsingFlags().outputFlags()
+
+	if code, ok := c.parseFlags(cm, args); !ok {
+		return code
+	}
+
+	if cm.fs.NArg() != 1 {
+		cm.fs.Usage()
+		return exitError
+	}
+
+	transactions, code := cm.load(c, cm.fs.Args(), false)
+	if code == exitError {
+		return code
+	}
+
+	return c.export(cm, transactions, code)
+}
+
+// parseFlags parses the command flags, returning false with the exit code
+// when the command should stop.
+func (c *cli) parseFlags(cm *common, args []string) (int, bool) {
+	err := cm.parse(args)
+
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK, false
+	case err != nil:
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return exitError, false
+	}
+
+	return exitOK, true
+}
+
+// export writes transactions in the chosen format, returning code on success.
+// Writing several files, per card or with the scheduled ones apart, lists
+// them on stderr.
+func (c *cli) export(cm *common, transactions []parser.Transaction, code int) int {
+	future := parser.FutureMode(cm.scheduled)
+	opts := append(cm.exportOptions(future), parser.WithSplit(parser.SplitMode(cm.split)))
+
+	files, err := parser.Render(parser.Format(cm.format), cm.qifType(), cm.output, transactions, opts...)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error generating %s: %v\n", cm.format, err)
+		return exitError
+	}
+
+	for _, f := range files {
+		if err := cm.writeTo(c, f.Name, f.Content); err != nil {
+			fmt.Fprintf(c.stderr, "error %v\n", err)
+			return exitError
+		}
+
+		switch {
+		case f.Scheduled:
+			fmt.Fprintf(c.stderr, "Wrote %d scheduled transaction(s) to %s\n", f.Transactions, f.Name)
+		case cm.split == string(parser.SplitFiles) || future == parser.FutureSeparate:
+			fmt.Fprintf(c.stderr, "Wrote %d transaction(s) to %s\n", f.Transactions, f.Name)
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/report"
+)
+
+// reportKind builds one kind of report from the loaded transactions.
+type reportKind struct {
+	name    string
+	summary string
+	build   func(cm *common, transactions []parser.Transaction) (table, error)
+}
+
+var reportKinds = []reportKind{
+	{"summary", "Totals of debits and credits by month, and how spending changed from the month before.", summaryReport},
+	{"cards", "Totals by card.", cardsReport},
+	{"categories", "Totals by category.", categoriesReport},
+	{"payees", "Totals of the payees spent the most on (see -top).", payeesReport},
+	{"cashflow", "Income and expenses of the account statement by month.", cashflowReport},
+	{"installments", "Installments still to be billed, by month and card, and when each purchase ends.", installmentsReport},
+	{"subscriptions", "Payees charged at a regular cadence, with price changes and missed charges.", subscriptionsReport},
+}
+
+func runReport(c *cli, args []string) int {
+	var kind reportKind
+
+	if len(args) > 0 {
+		if i := slices.IndexFunc(reportKinds, func(k reportKind) bool { return k.name == args[0] }); i >= 0 {
+			kind = reportKinds[i]
+			args = args[1:]
+		}
+	}
+
+	cm := c.newCommon("report", "<kind> <file1> [file2 ...]",
+		"Summarize transactions. Kinds:\n"+reportKindsHelp(),
+		[]string{formatText, string(parser.FormatCSV), string(parser.FormatJSON)}).parsingFlags().matchingFlags(true)
+
+	cm.fs.BoolVar(&cm.netRefunds, "net-refunds", false, "take refunds off the purchase they give back, leaving out fully refunded purchases")
+	cm.fs.IntVar(&cm.top, "top", 10, "how many payees the payees report lists, 0 for all")
+
+	if code, ok := c.parseFlags(cm, args); !ok {
+		return code
+	}
+
+	if kind.build == nil || cm.fs.NArg() == 0 {
+		cm.fs.Usage()
+		return exitError
+	}
+
+	transactions, code := cm.load(c, cm.fs.Args(), true)
+	if code == exitError {
+		return code
+	}
+
+	if cm.netRefunds {
+		var err error
+		if transactions, err = parser.NetRefunds(transactions); err != nil {
+			fmt.Fprintf(c.stderr, "error: %v\n", err)
+			return exitError
+		}
+	}
+
+	t, err := kind.build(cm, transactions)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error: %v\n", err)
+		return exitError
+	}
+
+	r, err := renderTable(cm.format, t)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "error generating %s: %v\n", cm.format, err)
+		return exitError
+	}
+
+	if err := cm.write(c, r); err != nil {
+		fmt.Fprintf(c.stderr, "error %v\n", err)
+		return exitError
+	}
+
+	return code
+}
+
+func reportKindsHelp() string {
+	var b strings.Builder
+
+	for _, k := range reportKinds {
+		fmt.Fprintf(&b, "  %-14s %s\n", k.name, k.summary)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func summaryReport(_ *common, transactions []parser.Transaction) (table, error) {
+	months, err := report.Monthly(transactions)
+	if err != nil {
+		return table{}, err
+	}
+
+	t := table{
+		Header: []string{"Month", "Transactions", "Debits", "Credits", "Net", "Change"},
+		JSON:   months,
+	}
+
+	for i, m := range months {
+		change := "-"
+		if i > 0 {
+			change = parser.FormatCents(m.Change)
+		}
+
+		t.Rows = append(t.Rows, []string{
+			m.Month.Format(monthFormat),
+			strconv.Itoa(m.Count),
+			parser.FormatCents(m.Debits),
+			parser.FormatCents(m.Credits),
+			parser.FormatCents(m.Net()),
+			change,
+		})
+	}
+
+	return t, nil
+}
+
+func cardsReport(_ *common, transactions []parser.Transaction) (table, error) {
+	totals, err := report.ByCard(transactions)
+
+	return totalsTable("Card", totals), err
+}
+
+func categoriesReport(_ *common, transactions []parser.Transaction) (table, error) {
+	totals, err := report.ByCategory(transactions)
+
+	return totalsTable("Category", totals), err
+}
+
+func payeesReport(cm *common, transactions []parser.Transaction) (table, error) {
+	totals, err := report.ByPayee(transactions, cm.top)
+
+	return totalsTable("Payee", totals), err
+}
+
+// totalsTable lists totals named by the name column, the ones without a
+// name as "-".
+func totalsTable(name string, totals []report.Total) table {
+	t := table{
+		Header: []string{name, "Transactions", "Debits", "Credits", "Net"},
+		JSON:   totals,
+	}
+
+	for _, total := range totals {
+		t.Rows = append(t.Rows, []string{
+			cmp.Or(total.Name, "-"),
+			strconv.Itoa(total.Count),
+			parser.FormatCents(total.Debits),
+			parser.FormatCents(total.Credits),
+			parser.FormatCents(total.Net()),
+		})
+	}
+
+	return t
+}
+
+func cashflowReport(_ *common, transactions []parser.Transaction) (table, error) {
+	flows, err := report.Cashflow(transactions)
+	if err != nil {
+		return table{}, err
+	}
+
+	t := table{
+		Header: []string{"Month", "Income", "Expenses", "Net"},
+		JSON:   flows,
+	}
+
+	for _, f := range flows {
+		t.Rows = append(t.Rows, []string{
+			f.Month.Format(monthFormat),
+			parser.FormatCents(f.Income),
+			parser.FormatCents(f.Expenses),
+			parser.FormatCents(f.Net()),
+		})
+	}
+
+	return t, nil
+}
+
+func installmentsReport(_ *common, transactions []parser.Transaction) (table, error) {
+	schedule, err := report.Installments(transactions)
+	if err != nil {
+		return table{}, err
+	}
+
+	months := table{
+		Header: []string{"Month", "Card", "Installments", "Amount"},
+		JSON:   schedule,
+	}
+
+	for _, c := range schedule.Months {
+		months.Rows = append(months.Rows, []string{
+			c.Month.Format(monthFormat),
+			c.Card,
+			strconv.Itoa(c.Count),
+			parser.FormatCents(c.Amount),
+		})
+	}
+
+	months.Rows = append(months.Rows, []string{"Total", "", "", parser.FormatCents(schedule.Owed)})
+
+	purchases := table{Header: []string{"Payee", "Card", "Installment", "Remaining", "Owed", "Ends"}}
+
+	for _, p := range schedule.Purchases {
+		purchases.Rows = append(purchases.Rows, []string{
+			p.Payee,
+			p.Card,
+			parser.FormatCents(p.Installment),
+			fmt.Sprintf("%d/%d", p.Remaining, p.Installments),
+			parser.FormatCents(p.Owed),
+			p.Ends.Format(monthFormat),
+		})
+	}
+
+	months.More = []table{purchases}
+
+	return months, nil
+}
+
+func subscriptionsReport(_ *common, transactions []parser.Transaction) (table, error) {
+	subscriptions, err := report.Subscriptions(transactions)
+	if err != nil {
+		return table{}, err
+	}
+
+	t := table{
+		Header: []string{"Payee", "Card", "Cadence", "Amount", "Charges", "First", "Last", "Next", "Status"},
+		JSON:   subscriptions,
+	}
+
+	changes := table{Header: []string{"Payee", "Date", "From", "To"}}
+
+	for _, s := range subscriptions {
+		t.Rows = append(t.Rows, []string{
+			s.Payee,
+			s.Card,
+			string(s.Cadence),
+			parser.FormatCents(s.Amount),
+			strconv.Itoa(s.Charges),
+			s.First.Format(dateFormat),
+			s.Last.Format(dateFormat),
+			s.Next.Format(dateFormat),
+			string(s.Status),
+		})
+
+		for _, c := range s.PriceChanges {
+			changes.Rows = append(changes.Rows, []string{
+				s.Payee,
+				c.Date.Format(dateFormat),
+				parser.FormatCents(c.From),
+				parser.FormatCents(c.To),
+			})
+		}
+	}
+
+	if len(changes.Rows) > 0 {
+		t.More = []table{changes}
+	}
+
+	return t, nil
+}
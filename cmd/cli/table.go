@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+// table is rendered output: a header and rows of already formatted cells.
+type table struct {
+	Header []string
+	Rows   [][]string
+	// JSON is the value encoded for -format json, keeping raw numbers.
+	JSON any
+	// More are tables rendered after this one, separated by a blank line.
+	// They are left out of JSON, which encodes the whole report.
+	More []table
+}
+
+func renderTable(format string, t table) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+
+	if format == string(parser.FormatJSON) {
+		encoder := json.NewEncoder(buf)
+		encoder.SetIndent("", "  ")
+
+		return buf, encoder.Encode(t.JSON)
+	}
+
+	for i, t := range append([]table{t}, t.More...) {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		if err := writeTable(buf, format, t); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+func writeTable(w io.Writer, format string, t table) error {
+	if format == string(parser.FormatCSV) {
+		writer := csv.NewWriter(w)
+		_ = writer.Write(t.Header)
+		_ = writer.WriteAll(t.Rows)
+
+		return writer.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, strings.Join(t.Header, "\t")+"\t")
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+
+	return tw.Flush()
+}
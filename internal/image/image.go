@@ -13,7 +13,10 @@ import (
 	"git.home/c6bank-transactions/internal/mobile"
 )
 
-var ErrUnsupportedPhone = errors.New("unsupported phone")
+var (
+	ErrUnsupportedPhone  = errors.New("unsupported phone")
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+)
 
 // HasTransparency checks if the first 10 pixels of the first row have alpha == 0.
 // Used to detect iPhone Mirror screenshots which have a transparent header.
@@ -42,29 +45,11 @@ func HasTransparency(img image.Image) bool {
 //   - month reference area (for extracting reference date/month)
 //
 // The function detects the phone model automatically and crops accordingly.
+// JPEG and PNG inputs are accepted; both regions are always re-encoded as PNG
+// so OCR never sees compression artifacts introduced by the crop itself.
 // Returns image readers for both regions and an error if processing fails.
 func Crop(file io.ReadSeeker) (io.Reader, io.Reader, error) {
-	var img image.Image
-	var err error
-
-	_, format, err := image.DecodeConfig(file)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if _, err = file.Seek(0, 0); err != nil {
-		return nil, nil, err
-	}
-
-	switch format {
-	case "jpg":
-		img, err = jpeg.Decode(file)
-	case "png":
-		img, err = png.Decode(file)
-	default:
-		return nil, nil, fmt.Errorf("unsupported image format: %s", format)
-	}
-
+	img, err := Decode(file)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -77,28 +62,39 @@ func Crop(file io.ReadSeeker) (io.Reader, io.Reader, error) {
 	croppedImg := CropImage(img, phone)
 	croppedMonth := CropMonth(img, phone)
 
-	var (
-		ierr, merr error
-		imageBuf   bytes.Buffer
-		monthBuf   bytes.Buffer
+	var imageBuf, monthBuf bytes.Buffer
+
+	err = errors.Join(
+		png.Encode(&imageBuf, croppedImg),
+		png.Encode(&monthBuf, croppedMonth),
 	)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	switch format {
-	case "jpg":
-		ierr = jpeg.Encode(&imageBuf, croppedImg, nil)
-		merr = jpeg.Encode(&monthBuf, croppedMonth, nil)
+	return &imageBuf, &monthBuf, nil
+}
 
-	case "png":
-		ierr = png.Encode(&imageBuf, croppedImg)
-		merr = png.Encode(&monthBuf, croppedMonth)
+// Decode reads a JPEG or PNG image, using the format reported by
+// image.DecodeConfig ("jpeg" or "png") to pick the decoder.
+func Decode(file io.ReadSeeker) (image.Image, error) {
+	_, format, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, err
 	}
 
-	err = errors.Join(ierr, merr)
-	if err != nil {
-		return nil, nil, err
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
 	}
 
-	return &imageBuf, &monthBuf, nil
+	switch format {
+	case "jpeg":
+		return jpeg.Decode(file)
+	case "png":
+		return png.Decode(file)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
 }
 
 // GetPhone detects the iPhone model from an image by dimensions and transparency.
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"image"
 	"image/color"
+	"image/gif"
+	"image/jpeg"
 	"image/png"
+	"os"
 	"testing"
 
 	subject "git.home/c6bank-transactions/internal/image"
@@ -325,3 +328,73 @@ func TestCrop_FullFlow(t *testing.T) {
 		assert.Equal(t, 100, croppedMonth.Bounds().Max.Y)
 	})
 }
+
+func TestCrop_Formats(t *testing.T) {
+	t.Parallel()
+
+	phone := mobile.IPhone16Pro
+
+	tests := []struct {
+		name    string
+		fixture string
+	}{
+		{"PNG screenshot", "../parser/testdata/IMG_0420.PNG"},
+		{"JPEG screenshot", "../../test/fixtures/IMG_0420.jpg"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fixture, err := os.Open(test.fixture)
+			require.NoError(t, err)
+			defer fixture.Close()
+
+			croppedImg, croppedMonth, err := subject.Crop(fixture)
+			require.NoError(t, err)
+
+			// crops are always re-encoded as PNG, whatever the input format
+			img, err := png.Decode(croppedImg)
+			require.NoError(t, err)
+			assert.Equal(t, phone.Width, img.Bounds().Max.X)
+			assert.Equal(t, phone.Height-phone.Header-phone.Footer, img.Bounds().Max.Y)
+
+			month, err := png.Decode(croppedMonth)
+			require.NoError(t, err)
+			assert.Equal(t, phone.Width, month.Bounds().Max.X)
+			assert.Equal(t, phone.MonthSize, month.Bounds().Max.Y)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JPEG", func(t *testing.T) {
+		t.Parallel()
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, 10, 20)), nil))
+
+		img, err := subject.Decode(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(10, 20), img.Bounds().Max)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		t.Parallel()
+
+		buf := bytes.NewBuffer(nil)
+		require.NoError(t, gif.Encode(buf, image.NewRGBA(image.Rect(0, 0, 10, 20)), nil))
+
+		_, err := subject.Decode(bytes.NewReader(buf.Bytes()))
+		assert.ErrorIs(t, err, subject.ErrUnsupportedFormat)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := subject.Decode(bytes.NewReader([]byte("plain text")))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Transaction is a plain-text accounting (ledger/hledger) entry with two
+// postings: Amount on the statement account and the balance on Expenses
+// (debits) or Income (credits).
+type Transaction struct {
+	Date    string // YYYY-MM-DD
+	Payee   string
+	Memo    string
+	Amount  string // dot decimal separator, negative for debits
+	Account string // the other side of the posting, defaults by sign
+}
+
+const (
+	DateFormat     = "2006-01-02"
+	Commodity      = "BRL"
+	BankAccount    = "Assets:C6 Bank:Checking"
+	CardAccount    = "Liabilities:C6 Bank:Credit Card"
+	ExpenseAccount = "Expenses:Unknown"
+	IncomeAccount  = "Income:Unknown"
+
+	FeesAccount       = "Expenses:Bank Fees"
+	SalaryAccount     = "Income:Salary"
+	InvestmentAccount = "Assets:C6 Bank:Investments"
+
+	// txFmt intentionally ends with a blank line between entries
+	txFmt = `{{.Date}} {{.Payee}}
+{{- if .Memo}}
+    ; {{.Memo}}
+{{- end}}
+    {{.Source}}  {{.Commodity}} {{.Amount}}
+    {{.Account}}
+
+`
+)
+
+var txTemplate = template.Must(template.New("txFmt").Parse(txFmt))
+
+type entry struct {
+	Transaction
+	Source    string
+	Commodity string
+}
+
+// Parse renders transactions as ledger entries posted against account.
+func Parse(account string, transactions []Transaction) (io.Reader, error) {
+	buff := new(bytes.Buffer)
+
+	for _, tx := range transactions {
+		if tx.Account == "" {
+			tx.Account = ExpenseAccount
+			if !strings.HasPrefix(tx.Amount, "-") {
+				tx.Account = IncomeAccount
+			}
+		}
+
+		if err := txTemplate.Execute(buff, entry{tx, account, Commodity}); err != nil {
+			return nil, err
+		}
+	}
+
+	return buff, nil
+}
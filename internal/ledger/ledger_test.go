@@ -0,0 +1,43 @@
+package ledger_test
+
+import (
+	"io"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/ledger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	transactions := []ledger.Transaction{
+		{Date: "1111-01-01", Payee: "with memo", Memo: "memo", Amount: "-123.45"},
+		{Date: "2222-02-02", Payee: "refund", Amount: "987.65"},
+		{Date: "3333-03-03", Payee: "transfer", Amount: "-1.00", Account: "Assets:Savings"},
+	}
+
+	rendered := `1111-01-01 with memo
+    ; memo
+    Liabilities:C6 Bank:Credit Card  BRL -123.45
+    Expenses:Unknown
+
+2222-02-02 refund
+    Liabilities:C6 Bank:Credit Card  BRL 987.65
+    Income:Unknown
+
+3333-03-03 transfer
+    Liabilities:C6 Bank:Credit Card  BRL -1.00
+    Assets:Savings
+
+`
+
+	parsed, err := ledger.Parse(ledger.CardAccount, transactions)
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(parsed)
+	require.NoError(t, err)
+
+	assert.Equal(t, rendered, string(output))
+}
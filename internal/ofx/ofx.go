@@ -0,0 +1,168 @@
+package ofx
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"text/template"
+)
+
+type Transaction struct {
+	ID     string
+	Type   TransactionType
+	Date   string // YYYYMMDD
+	Amount string // dot decimal separator, negative for debits
+	Name   string
+	Memo   string
+}
+
+type (
+	AccountType     string
+	TransactionType string
+)
+
+const (
+	BankType       AccountType = "BANK"       // <BANKMSGSRSV1>       | Checking account statement
+	CreditCardType AccountType = "CREDITCARD" // <CREDITCARDMSGSRSV1> | Credit card statement
+	DateFormat                 = "20060102"
+
+	Credit        TransactionType = "CREDIT"
+	Debit         TransactionType = "DEBIT"
+	Xfer          TransactionType = "XFER"
+	Payment       TransactionType = "PAYMENT"
+	POS           TransactionType = "POS"
+	Fee           TransactionType = "FEE"
+	DirectDeposit TransactionType = "DIRECTDEP"
+
+	// bankID is C6 Bank's code in the Brazilian payment system
+	bankID    = "336"
+	accountID = "C6BANK"
+)
+
+// Balance is the LEDGERBAL of a statement, the account balance at Date.
+type Balance struct {
+	Amount string // dot decimal separator
+	Date   string // YYYYMMDD, defaults to the last transaction date
+}
+
+type statement struct {
+	Account      AccountType
+	BankID       string
+	AccountID    string
+	Start, End   string
+	Transactions []Transaction
+	Balance      *Balance
+}
+
+// stmtFmt is an OFX 1.02 (SGML) statement, the version most finance apps import.
+const stmtFmt = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:UTF-8
+CHARSET:NONE
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<DTSERVER>{{.End}}
+<LANGUAGE>POR
+</SONRS></SIGNONMSGSRSV1>
+{{- if eq .Account "BANK"}}
+<BANKMSGSRSV1><STMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS>
+<CURDEF>BRL
+<BANKACCTFROM><BANKID>{{.BankID}}<ACCTID>{{.AccountID}}<ACCTTYPE>CHECKING</BANKACCTFROM>
+{{- else}}
+<CREDITCARDMSGSRSV1><CCSTMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<CCSTMTRS>
+<CURDEF>BRL
+<CCACCTFROM><ACCTID>{{.AccountID}}</CCACCTFROM>
+{{- end}}
+<BANKTRANLIST>
+<DTSTART>{{.Start}}
+<DTEND>{{.End}}
+{{- range .Transactions}}
+<STMTTRN>
+<TRNTYPE>{{.Type}}
+<DTPOSTED>{{.Date}}
+<TRNAMT>{{.Amount}}
+<FITID>{{.ID | escape}}
+<NAME>{{.Name | escape}}
+{{- if .Memo}}
+<MEMO>{{.Memo | escape}}
+{{- end}}
+</STMTTRN>
+{{- end}}
+</BANKTRANLIST>
+{{- with .Balance}}
+<LEDGERBAL>
+<BALAMT>{{.Amount}}
+<DTASOF>{{.Date}}
+</LEDGERBAL>
+{{- end}}
+{{- if eq .Account "BANK"}}
+</STMTRS>
+</STMTTRNRS></BANKMSGSRSV1>
+{{- else}}
+</CCSTMTRS>
+</CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+{{- end}}
+</OFX>
+`
+
+var (
+	escaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	stmtTemplate = template.Must(template.New("stmtFmt").
+			Funcs(template.FuncMap{"escape": escaper.Replace}).
+			Parse(stmtFmt))
+)
+
+func Parse(atype AccountType, transactions []Transaction) (io.Reader, error) {
+	return parse(atype, transactions, nil)
+}
+
+// ParseWithBalance renders the statement with its closing balance.
+func ParseWithBalance(atype AccountType, transactions []Transaction, balance Balance) (io.Reader, error) {
+	return parse(atype, transactions, &balance)
+}
+
+func parse(atype AccountType, transactions []Transaction, balance *Balance) (io.Reader, error) {
+	stmt := statement{
+		Account:      atype,
+		BankID:       bankID,
+		AccountID:    accountID,
+		Transactions: transactions,
+	}
+
+	for _, tx := range transactions {
+		if stmt.Start == "" || tx.Date < stmt.Start {
+			stmt.Start = tx.Date
+		}
+
+		if tx.Date > stmt.End {
+			stmt.End = tx.Date
+		}
+	}
+
+	if balance != nil && balance.Date == "" {
+		balance.Date = stmt.End
+	}
+
+	stmt.Balance = balance
+
+	buff := new(bytes.Buffer)
+
+	if err := stmtTemplate.Execute(buff, stmt); err != nil {
+		return nil, err
+	}
+
+	return buff, nil
+}
@@ -0,0 +1,139 @@
+package ofx_test
+
+import (
+	"io"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/ofx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const header = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:UTF-8
+CHARSET:NONE
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<DTSERVER>22220202
+<LANGUAGE>POR
+</SONRS></SIGNONMSGSRSV1>
+`
+
+const renderedTx = `<BANKTRANLIST>
+<DTSTART>11110101
+<DTEND>22220202
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>22220202
+<TRNAMT>-987.65
+<FITID>2
+<NAME>without memo
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>11110101
+<TRNAMT>123.45
+<FITID>1
+<NAME>with memo &amp; &lt;escaping&gt;
+<MEMO>memo
+</STMTTRN>
+</BANKTRANLIST>
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	transactions := []ofx.Transaction{
+		{ID: "2", Type: ofx.Debit, Date: "22220202", Amount: "-987.65", Name: "without memo"},
+		{ID: "1", Type: ofx.Credit, Date: "11110101", Amount: "123.45", Name: "with memo & <escaping>", Memo: "memo"},
+	}
+
+	tests := []struct {
+		atype  ofx.AccountType
+		before string
+		after  string
+	}{
+		{
+			atype: ofx.BankType,
+			before: `<BANKMSGSRSV1><STMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS>
+<CURDEF>BRL
+<BANKACCTFROM><BANKID>336<ACCTID>C6BANK<ACCTTYPE>CHECKING</BANKACCTFROM>
+`,
+			after: `</STMTRS>
+</STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`,
+		},
+		{
+			atype: ofx.CreditCardType,
+			before: `<CREDITCARDMSGSRSV1><CCSTMTTRNRS>
+<TRNUID>1
+<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<CCSTMTRS>
+<CURDEF>BRL
+<CCACCTFROM><ACCTID>C6BANK</CCACCTFROM>
+`,
+			after: `</CCSTMTRS>
+</CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.atype), func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := ofx.Parse(tt.atype, transactions)
+			require.NoError(t, err)
+
+			output, err := io.ReadAll(parsed)
+			require.NoError(t, err)
+
+			assert.Equal(t, header+tt.before+renderedTx+tt.after, string(output))
+		})
+	}
+}
+
+func TestParseWithBalance(t *testing.T) {
+	t.Parallel()
+
+	transactions := []ofx.Transaction{
+		{ID: "1", Type: ofx.Credit, Date: "20260302", Amount: "500.00", Name: "pix"},
+		{ID: "2", Type: ofx.Debit, Date: "20260305", Amount: "-50.00", Name: "ted"},
+	}
+
+	tests := []struct {
+		name    string
+		balance ofx.Balance
+		want    string
+	}{
+		{"dated", ofx.Balance{Amount: "1450.00", Date: "20260331"}, "<BALAMT>1450.00\n<DTASOF>20260331\n"},
+		{"last transaction date", ofx.Balance{Amount: "-10.00"}, "<BALAMT>-10.00\n<DTASOF>20260305\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed, err := ofx.ParseWithBalance(ofx.BankType, transactions, tt.balance)
+			require.NoError(t, err)
+
+			output, err := io.ReadAll(parsed)
+			require.NoError(t, err)
+
+			assert.Contains(t, string(output), "</BANKTRANLIST>\n<LEDGERBAL>\n"+tt.want+"</LEDGERBAL>\n</STMTRS>")
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package ofx
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidOFX = errors.New("invalid OFX")
+
+	regexStatementTx = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	// regexElement matches both SGML (<NAME>value) and XML (<NAME>value</NAME>) elements
+	regexElement = regexp.MustCompile(`<([A-Za-z0-9.]+)>([^<\r\n]*)`)
+
+	unescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+)
+
+// Read parses the transactions of an OFX statement, either SGML (1.x) as
+// written by Parse or XML (2.x).
+func Read(r io.Reader) ([]Transaction, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(strings.ToUpper(string(data)), "<OFX>") {
+		return nil, ErrInvalidOFX
+	}
+
+	var transactions []Transaction
+
+	for _, match := range regexStatementTx.FindAllStringSubmatch(string(data), -1) {
+		var tx Transaction
+
+		for _, element := range regexElement.FindAllStringSubmatch(match[1], -1) {
+			value := unescaper.Replace(strings.TrimSpace(element[2]))
+
+			switch strings.ToUpper(element[1]) {
+			case "TRNTYPE":
+				tx.Type = TransactionType(value)
+			case "DTPOSTED":
+				if len(value) < len(DateFormat) {
+					return nil, ErrInvalidOFX
+				}
+
+				tx.Date = value[:len(DateFormat)] // drop time and timezone
+			case "TRNAMT":
+				tx.Amount = value
+			case "FITID":
+				tx.ID = value
+			case "NAME", "PAYEE":
+				tx.Name = value
+			case "MEMO":
+				tx.Memo = value
+			}
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,47 @@
+package ofx_test
+
+import (
+	"strings"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/ofx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	transactions := []ofx.Transaction{
+		{ID: "2", Type: ofx.Debit, Date: "22220202", Amount: "-987.65", Name: "without memo"},
+		{ID: "1", Type: ofx.Credit, Date: "11110101", Amount: "123.45", Name: "with memo & <escaping>", Memo: "memo"},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+
+		parsed, err := ofx.Parse(ofx.CreditCardType, transactions)
+		require.NoError(t, err)
+
+		got, err := ofx.Read(parsed)
+		require.NoError(t, err)
+		assert.Equal(t, transactions, got)
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ofx.Read(strings.NewReader(`<?xml version="1.0"?><OFX><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260301120000[-3:BRT]</DTPOSTED><TRNAMT>-10.00</TRNAMT><FITID>a</FITID><NAME>PIX</NAME></STMTTRN>
+</BANKTRANLIST></OFX>`))
+		require.NoError(t, err)
+		assert.Equal(t, []ofx.Transaction{{ID: "a", Type: ofx.Debit, Date: "20260301", Amount: "-10.00", Name: "PIX"}}, got)
+	})
+
+	t.Run("not OFX", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ofx.Read(strings.NewReader("Date,Payee,Memo,Value\n"))
+		require.ErrorIs(t, err, ofx.ErrInvalidOFX)
+	})
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseAmount converts an amount to cents. It accepts the formats C6 uses,
+// with a comma decimal separator and optional dot thousands separator
+// ("-1.234,56", "167,91"), as well as a dot decimal separator ("123.45").
+func ParseAmount(amount string) (int64, error) {
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(amount), "R$"))
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+
+	if strings.Contains(text, coma) {
+		text = strings.ReplaceAll(text, ".", "")
+		text = strings.Replace(text, coma, ".", 1)
+	}
+
+	units, fraction, _ := strings.Cut(text, ".")
+	if units == "" || len(fraction) > 2 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	cents, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil || strings.ContainsAny(units+fraction, "+-") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	if negative {
+		cents = -cents
+	}
+
+	return cents, nil
+}
+
+// FormatCents formats cents with a dot decimal separator, e.g. "-1234.56".
+func FormatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// formatBRL formats cents the way C6 does, e.g. "-1.234,56".
+func formatBRL(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	units := strconv.FormatInt(cents/100, 10)
+	for i := len(units) - 3; i > 0; i -= 3 {
+		units = units[:i] + "." + units[i:]
+	}
+
+	return fmt.Sprintf("%s%s,%02d", sign, units, cents%100)
+}
+
+// parseRate parses an exchange rate such as "5,4321".
+func parseRate(rate string) (float64, error) {
+	text := strings.TrimSpace(rate)
+	if strings.Contains(text, coma) {
+		text = strings.ReplaceAll(text, ".", "")
+		text = strings.Replace(text, coma, ".", 1)
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%w: exchange rate %q", ErrInvalidAmount, rate)
+	}
+
+	return value, nil
+}
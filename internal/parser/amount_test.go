@@ -0,0 +1,49 @@
+package parser_test
+
+import (
+	"testing"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAmount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		amount string
+		cents  int64
+		err    error
+	}{
+		{"167,91", 16791, nil},
+		{"-167,91", -16791, nil},
+		{"1.234,56", 123456, nil},
+		{"-1.234.567,8", -123456780, nil},
+		{"R$ 64,24", 6424, nil},
+		{"123.45", 12345, nil},
+		{"10", 1000, nil},
+		{"", 0, parser.ErrInvalidAmount},
+		{"abc", 0, parser.ErrInvalidAmount},
+		{"1,234", 0, parser.ErrInvalidAmount},
+		{"--5,00", 0, parser.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			t.Parallel()
+
+			cents, err := parser.ParseAmount(tt.amount)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.cents, cents)
+		})
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0.00", parser.FormatCents(0))
+	assert.Equal(t, "0.05", parser.FormatCents(5))
+	assert.Equal(t, "-167.91", parser.FormatCents(-16791))
+	assert.Equal(t, "1234.50", parser.FormatCents(123450))
+}
@@ -0,0 +1,138 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Categories of the cost splits written by SplitCosts.
+const (
+	CategoryIOF  = "IOF"
+	CategoryFees = "Juros e tarifas"
+)
+
+var regexCost = regexp.MustCompile(`(?i)\b(IOF|JUROS|ENCARGOS|TARIFA)\b`)
+
+// Split is a part of a transaction amount, written as QIF split lines.
+type Split struct {
+	Category string `json:"category"`
+	Memo     string `json:"memo,omitempty"`
+	Amount   string `json:"amount"`
+}
+
+// SplitCosts breaks installment and international purchases into principal
+// and costs. IOF, interest and fee rows charged on the same day and card as
+// one of those purchases are folded into it, and the difference between an
+// international purchase and its US$ value at the exchange rate is kept as a
+// fee. Purchases without costs are left unsplit.
+func SplitCosts(transactions []Transaction) ([]Transaction, error) {
+	costs := make(map[int][]Transaction)
+	folded := make(map[int]bool)
+
+	for i, t := range transactions {
+		if !regexCost.MatchString(t.Payee) {
+			continue
+		}
+
+		for j, purchase := range transactions {
+			if isCostTarget(purchase) && j != i && purchase.Card == t.Card &&
+				purchase.Date.Equal(t.Date) && purchase.Future == t.Future {
+				costs[j] = append(costs[j], t)
+				folded[i] = true
+
+				break
+			}
+		}
+	}
+
+	result := make([]Transaction, 0, len(transactions)-len(folded))
+
+	for i, t := range transactions {
+		if folded[i] {
+			continue
+		}
+
+		if isCostTarget(t) {
+			var err error
+			if t, err = splitCosts(t, costs[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+func isCostTarget(t Transaction) bool {
+	return (t.Installment || t.ForeignAmount != "") && !regexCost.MatchString(t.Payee)
+}
+
+func splitCosts(t Transaction, costs []Transaction) (Transaction, error) {
+	total, err := ParseAmount(t.Amount)
+	if err != nil {
+		return t, err
+	}
+
+	principal := Split{Category: t.Category, Memo: t.Memo, Amount: t.Amount}
+	splits := []Split{principal}
+
+	if t.ForeignAmount != "" && t.ExchangeRate != "" {
+		converted, err := convert(t.ForeignAmount, t.ExchangeRate, total)
+		if err != nil {
+			return t, err
+		}
+
+		splits[0].Memo = fmt.Sprintf("US$ %s x %s", strings.TrimPrefix(t.ForeignAmount, "-"), t.ExchangeRate)
+		splits[0].Amount = formatBRL(converted)
+
+		if spread := total - converted; spread != 0 {
+			splits = append(splits, Split{Category: CategoryFees, Memo: "spread", Amount: formatBRL(spread)})
+		}
+	}
+
+	for _, cost := range costs {
+		cents, err := ParseAmount(cost.Amount)
+		if err != nil {
+			return t, err
+		}
+
+		category := CategoryFees
+		if strings.Contains(strings.ToUpper(cost.Payee), CategoryIOF) {
+			category = CategoryIOF
+		}
+
+		total += cents
+		splits = append(splits, Split{Category: category, Memo: cost.Payee, Amount: cost.Amount})
+	}
+
+	if len(splits) > 1 {
+		t.Amount = formatBRL(total)
+		t.Splits = splits
+	}
+
+	return t, nil
+}
+
+// convert returns the R$ cents of a US$ amount, with the sign of total.
+func convert(foreign, rate string, total int64) (int64, error) {
+	cents, err := ParseAmount(foreign)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := parseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := int64(math.Round(math.Abs(float64(cents)) * value))
+	if total < 0 {
+		converted = -converted
+	}
+
+	return converted, nil
+}
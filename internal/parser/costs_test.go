@@ -0,0 +1,62 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCosts(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "STEAM", Amount: "-56,00", Card: "1234", Category: "Jogos", ForeignAmount: "10,00", ExchangeRate: "5,5"},
+		{Date: date, Payee: "IOF COMPRA INTERNACIONAL", Amount: "-1,96", Card: "1234"},
+		{Date: date, Payee: "IOF COMPRA INTERNACIONAL", Amount: "-9,99", Card: "9999"},
+		{Date: date, Payee: "LOJA", Amount: "-100,00", Card: "4321", Memo: "2/10 4321 03/2026", Installment: true},
+		{Date: date, Payee: "JUROS PARCELAMENTO", Amount: "-5,00", Card: "4321"},
+		{Date: date, Payee: "MERCADO", Amount: "-1.000,00", Card: "1234"},
+		{Date: date, Payee: "AMAZON", Amount: "-50,00", Card: "1234", Installment: true},
+	}
+
+	got, err := parser.SplitCosts(transactions)
+	require.NoError(t, err)
+	require.Len(t, got, 5)
+
+	steam := got[0]
+	assert.Equal(t, "-57,96", steam.Amount)
+	assert.Equal(t, []parser.Split{
+		{Category: "Jogos", Memo: "US$ 10,00 x 5,5", Amount: "-55,00"},
+		{Category: parser.CategoryFees, Memo: "spread", Amount: "-1,00"},
+		{Category: parser.CategoryIOF, Memo: "IOF COMPRA INTERNACIONAL", Amount: "-1,96"},
+	}, steam.Splits)
+
+	// an IOF row on another card has no purchase to fold into
+	assert.Equal(t, "IOF COMPRA INTERNACIONAL", got[1].Payee)
+	assert.Empty(t, got[1].Splits)
+
+	loja := got[2]
+	assert.Equal(t, "-105,00", loja.Amount)
+	assert.Equal(t, []parser.Split{
+		{Memo: "2/10 4321 03/2026", Amount: "-100,00"},
+		{Category: parser.CategoryFees, Memo: "JUROS PARCELAMENTO", Amount: "-5,00"},
+	}, loja.Splits)
+
+	assert.Empty(t, got[3].Splits)
+	assert.Equal(t, "AMAZON", got[4].Payee)
+	assert.Empty(t, got[4].Splits, "installments without costs are left unsplit")
+}
+
+func TestSplitCosts_InvalidRate(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.SplitCosts([]parser.Transaction{
+		{Payee: "STEAM", Amount: "-56,00", ForeignAmount: "10,00", ExchangeRate: "x"},
+	})
+	require.ErrorIs(t, err, parser.ErrInvalidAmount)
+}
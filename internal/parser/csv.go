@@ -3,8 +3,10 @@ package parser
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -21,87 +23,150 @@ const (
 	unique     = "Única"
 )
 
-func scanCSVRows(reference time.Time, file io.Reader) ([]Line, error) {
+// scanCSVRows reads the invoice CSV. A first installment ("1/N") is expanded
+// into the N monthly installments, the later ones flagged as Future. Blank
+// rows are ignored and rows with invalid dates or installments are returned
+// as skipped.
+func scanCSVRows(reference time.Time, file io.Reader) ([]Transaction, []SkippedRow, error) {
 	csvReader := csv.NewReader(file)
 	csvReader.Comma = ';'
 
 	_, err := csvReader.Read() // header
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var lines []Line
+	var (
+		transactions []Transaction
+		skipped      []SkippedRow
+	)
 
-	for {
+	for row := 1; ; row++ {
 		record, err := csvReader.Read()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		fixValue(&record[8])
-
-		if record[5] == unique {
-			record[5] = parseMemo(reference, record[2], 0, 0)
-		} else {
-			if err = handleInstallments(reference, record, &lines); err != nil {
-				return nil, err
-			}
-
+		// blank rows hold no transaction, so they are not worth reporting
+		if !slices.ContainsFunc(record, func(field string) bool { return strings.TrimSpace(field) != "" }) {
 			continue
 		}
 
-		date, err := time.Parse(dateFormat, record[0])
-		if err != nil || date.IsZero() {
-			continue // Skip appending if the date is empty or invalid
+		appendInvoiceRecord(reference, row, record, &transactions, &skipped)
+	}
+
+	return transactions, skipped, nil
+}
+
+// appendInvoiceRecord appends the transactions of an invoice row in the
+// layout of the CSV, whatever file it comes from, or adds it to skipped.
+func appendInvoiceRecord(reference time.Time, row int, record []string, transactions *[]Transaction, skipped *[]SkippedRow) {
+	fixValue(&record[8])
+
+	date, err := time.Parse(dateFormat, record[0])
+	if err != nil || date.IsZero() {
+		skipRecord(skipped, row, record, fmt.Sprintf("invalid date %q", record[0]))
+		return
+	}
+
+	if record[5] != unique {
+		current, total, ok := parseInstallment(record[5])
+		if !ok {
+			skipRecord(skipped, row, record, fmt.Sprintf("invalid installment %q", record[5]))
+			return
 		}
 
-		lines = append(lines, Line{record[0], record[4], record[5], record[8]})
+		handleInstallments(reference, date, current, total, record, transactions)
+
+		return
 	}
 
-	return lines, nil
+	*transactions = append(*transactions, Transaction{
+		Date:     date,
+		Payee:    record[4],
+		Memo:     parseMemo(reference, record[2], 0, 0),
+		Amount:   record[8],
+		Card:     record[2],
+		CardName: record[1],
+		Category: record[3],
+
+		ForeignAmount: record[6],
+		ExchangeRate:  record[7],
+	})
 }
 
-func handleInstallments(reference time.Time, record []string, lines *[]Line) error {
-	purchase, card, payee, installment, value := record[0], record[2], record[4], record[5], record[8]
-
-	parts := strings.SplitN(installment, "/", 2)
+// skipRecord adds an invoice row that could not be read to skipped.
+func skipRecord(skipped *[]SkippedRow, row int, record []string, reason string) {
+	*skipped = append(*skipped, SkippedRow{
+		Row:    row,
+		Line:   Line{record[0], record[4], record[5], record[8]},
+		Reason: reason,
+	})
+}
 
-	current, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return err
+// parseInstallment reads "2/10", the second of ten installments.
+func parseInstallment(installment string) (current, total int, ok bool) {
+	c, t, ok := strings.Cut(installment, "/")
+	if !ok {
+		return 0, 0, false
 	}
 
-	total, err := strconv.Atoi(parts[1])
+	current, err := strconv.Atoi(strings.TrimSpace(c))
 	if err != nil {
-		return err
+		return 0, 0, false
 	}
 
-	date, err := time.Parse(dateFormat, purchase)
-	if err != nil {
-		return err
+	total, err = strconv.Atoi(strings.TrimSpace(t))
+	if err != nil || current < 1 || current > total {
+		return 0, 0, false
 	}
 
+	return current, total, true
+}
+
+func handleInstallments(reference, date time.Time, current, total int, record []string, transactions *[]Transaction) {
+	name, card, category, payee, value := record[1], record[2], record[3], record[4], record[8]
+	foreign, rate := record[6], record[7]
+
 	if current > 1 {
-		dateFixed := date.AddDate(0, current-1, 0)
-		*lines = append(*lines, Line{
-			dateFixed.Format(dateFormat), payee, parseMemo(reference, card, current, total),
-			value,
+		*transactions = append(*transactions, Transaction{
+			Date:        date.AddDate(0, current-1, 0),
+			Payee:       payee,
+			Memo:        parseMemo(reference, card, current, total),
+			Amount:      value,
+			Card:        card,
+			CardName:    name,
+			Category:    category,
+			Installment: true,
+
+			ForeignAmount: foreign,
+			ExchangeRate:  rate,
 		})
 
-		return nil
+		return
 	}
 
 	for ; current <= total; current++ {
 		ref := reference.AddDate(0, current-1, 0)
-		dateFixed := date.AddDate(0, current-1, 0)
 		memo := fmt.Sprintf("%d/%d %s %02d/%04d", current, total, card, ref.Month(), ref.Year())
 
-		*lines = append(*lines, Line{dateFixed.Format(dateFormat), payee, memo, value})
+		*transactions = append(*transactions, Transaction{
+			Date:        date.AddDate(0, current-1, 0),
+			Payee:       payee,
+			Memo:        memo,
+			Amount:      value,
+			Card:        card,
+			CardName:    name,
+			Category:    category,
+			Installment: true,
+			Future:      current > 1,
+
+			ForeignAmount: foreign,
+			ExchangeRate:  rate,
+		})
 	}
-
-	return nil
 }
 
 func fixValue(value *string) {
@@ -131,18 +196,69 @@ func parseMemo(ref time.Time, card string, current, total int) string {
 	return buf.String()
 }
 
+var (
+	ErrInvalidExport = errors.New("not a CSV exported by this tool")
+
+	csvHeader = []string{"Date", "Payee", "Memo", "Value"}
+	// csvColumns are the optional columns after csvHeader, in this order
+	csvColumns = []string{idColumn, scheduledColumn, categoryColumn}
+)
+
+const (
+	idColumn        = "ID"
+	scheduledColumn = "Scheduled"
+	categoryColumn  = "Category"
+)
+
 func TransactionsToCSV(transactions []Transaction) (io.Reader, error) {
+	return transactionsToCSV(transactions, false, false)
+}
+
+// transactionsToCSV writes the CSV export, with the Scheduled column when
+// marked. With columns, it adds the ID column and, when some transaction
+// has a category hint, the Category column.
+func transactionsToCSV(transactions []Transaction, marked, columns bool) (io.Reader, error) {
 	buf := new(bytes.Buffer)
 
 	writer := csv.NewWriter(buf)
 	// writer.Comma = ';'
 
-	if err := writer.Write([]string{"Date", "Payee", "Memo", "Value"}); err != nil {
+	categories := columns && slices.ContainsFunc(transactions, func(t Transaction) bool {
+		return t.categoryHint() != ""
+	})
+
+	header := slices.Clip(csvHeader)
+	if columns {
+		header = append(header, idColumn)
+	}
+
+	if marked {
+		header = append(header, scheduledColumn)
+	}
+
+	if categories {
+		header = append(header, categoryColumn)
+	}
+
+	if err := writer.Write(header); err != nil {
 		return nil, err
 	}
 
 	for _, ts := range transactions {
-		if err := writer.Write(ts.CSVLine()); err != nil {
+		line := ts.CSVLine()
+		if columns {
+			line = append(line, ts.ID)
+		}
+
+		if marked {
+			line = append(line, strconv.FormatBool(ts.Future))
+		}
+
+		if categories {
+			line = append(line, ts.categoryHint())
+		}
+
+		if err := writer.Write(line); err != nil {
 			return nil, err
 		}
 	}
@@ -155,3 +271,76 @@ func TransactionsToCSV(transactions []Transaction) (io.Reader, error) {
 
 	return buf, nil
 }
+
+// isCSVHeader tells whether header is csvHeader followed by some of the
+// csvColumns, in order.
+func isCSVHeader(header []string) bool {
+	if len(header) < len(csvHeader) || !slices.Equal(header[:len(csvHeader)], csvHeader) {
+		return false
+	}
+
+	columns := csvColumns
+	for _, column := range header[len(csvHeader):] {
+		i := slices.Index(columns, column)
+		if i < 0 {
+			return false
+		}
+
+		columns = columns[i+1:]
+	}
+
+	return true
+}
+
+// ReadCSV reads back the transactions of a CSV written by TransactionsToCSV,
+// with or without the ID, Scheduled and Category columns.
+func ReadCSV(file io.Reader) ([]Transaction, error) {
+	reader := csv.NewReader(file)
+
+	header, err := reader.Read()
+	if err != nil || !isCSVHeader(header) {
+		return nil, ErrInvalidExport
+	}
+
+	id := slices.Index(header, idColumn)
+	scheduled, category := slices.Index(header, scheduledColumn), slices.Index(header, categoryColumn)
+
+	var transactions []Transaction
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse(dateFormat, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidExport, err)
+		}
+
+		t := Transaction{
+			Date:   date,
+			Payee:  record[1],
+			Memo:   record[2],
+			Amount: record[3],
+		}
+
+		if id > 0 {
+			t.ID = record[id]
+		}
+
+		if scheduled > 0 {
+			t.Future = record[scheduled] == "true"
+		}
+
+		if category > 0 {
+			t.Category = record[category]
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	return transactions, nil
+}
@@ -2,6 +2,8 @@ package parser_test
 
 import (
 	"encoding/csv"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +27,7 @@ func TestTransactionsToCSV(t *testing.T) {
 	require.NoError(t, err)
 
 	header := csvLines[0]
+	require.Len(t, header, 4, "the ID and Category columns are opt-in")
 	assert.Equal(t, "Date", header[0])
 	assert.Equal(t, "Payee", header[1])
 	assert.Equal(t, "Memo", header[2])
@@ -36,3 +39,44 @@ func TestTransactionsToCSV(t *testing.T) {
 	assert.Equal(t, transaction.Memo, line[2])
 	assert.Equal(t, transaction.Amount, line[3])
 }
+
+func TestReadCSV(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Payee: "MERCADO, EXTRA", Memo: "1234 01/2026", Amount: "-167,91"},
+		{Date: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), Payee: "AMAZON BR", Memo: "1/3 5678 01/2026", Amount: "-50,00"},
+	}
+
+	reader, err := parser.TransactionsToCSV(transactions)
+	require.NoError(t, err)
+
+	got, err := parser.ReadCSV(reader)
+	require.NoError(t, err)
+	assert.Equal(t, transactions, got)
+
+	t.Run("invoice CSV", func(t *testing.T) {
+		t.Parallel()
+
+		invoice, err := os.Open("testdata/Fatura_2026-01-15.csv")
+		require.NoError(t, err)
+		defer invoice.Close()
+
+		_, err = parser.ReadCSV(invoice)
+		assert.ErrorIs(t, err, parser.ErrInvalidExport)
+	})
+
+	t.Run("unknown column order", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.ReadCSV(strings.NewReader("Date,Payee,Memo,Value,Category,ID\n01/01/2026,A,,1,,1\n"))
+		assert.ErrorIs(t, err, parser.ErrInvalidExport)
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.ReadCSV(strings.NewReader("Date,Payee,Memo,Value\n2026-01-01,A,,1\n"))
+		assert.ErrorIs(t, err, parser.ErrInvalidExport)
+	})
+}
@@ -0,0 +1,74 @@
+package parser
+
+// Change is a transaction found in both sides of a Diff with another amount.
+type Change struct {
+	Old, New Transaction
+}
+
+// DiffResult lists what changed from an old set of transactions to a new one.
+type DiffResult struct {
+	Added   []Transaction
+	Removed []Transaction
+	Changed []Change
+}
+
+// Diff compares two sets of transactions. Transactions are identified by
+// date, payee and memo; the same identity with another amount is a change.
+// Repeated transactions (two identical coffees on the same day) are paired
+// one to one, so a third coffee is reported as added.
+func Diff(old, new []Transaction) DiffResult {
+	var result DiffResult
+
+	matched := make([]bool, len(old))
+	pending := make([]Transaction, 0, len(new))
+
+	// exact matches first, so a changed amount never steals the pair of an
+	// unchanged transaction with the same identity
+	for _, n := range new {
+		if i := unmatched(old, matched, n, true); i >= 0 {
+			matched[i] = true
+			continue
+		}
+
+		pending = append(pending, n)
+	}
+
+	for _, n := range pending {
+		if i := unmatched(old, matched, n, false); i >= 0 {
+			matched[i] = true
+			result.Changed = append(result.Changed, Change{Old: old[i], New: n})
+
+			continue
+		}
+
+		result.Added = append(result.Added, n)
+	}
+
+	for i, o := range old {
+		if !matched[i] {
+			result.Removed = append(result.Removed, o)
+		}
+	}
+
+	return result
+}
+
+func unmatched(old []Transaction, matched []bool, t Transaction, sameAmount bool) int {
+	for i, o := range old {
+		if matched[i] || diffKey(o) != diffKey(t) {
+			continue
+		}
+
+		if sameAmount && o.Amount != t.Amount {
+			continue
+		}
+
+		return i
+	}
+
+	return -1
+}
+
+func diffKey(t Transaction) string {
+	return t.Date.Format(dateFormat) + "\x00" + t.Payee + "\x00" + t.Memo
+}
@@ -0,0 +1,36 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	coffee := parser.Transaction{Date: date, Payee: "CAFE", Memo: "1234 01/2026", Amount: "-5,00"}
+	market := parser.Transaction{Date: date, Payee: "MERCADO", Memo: "1234 01/2026", Amount: "-100,00"}
+	fixed := market
+	fixed.Amount = "-110,00"
+	gone := parser.Transaction{Date: date, Payee: "CANCELADO", Amount: "-1,00"}
+
+	result := parser.Diff(
+		[]parser.Transaction{coffee, market, gone},
+		[]parser.Transaction{coffee, coffee, fixed},
+	)
+
+	assert.Equal(t, []parser.Transaction{coffee}, result.Added, "the second coffee is new")
+	assert.Equal(t, []parser.Transaction{gone}, result.Removed)
+	assert.Equal(t, []parser.Change{{Old: market, New: fixed}}, result.Changed)
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+
+		result := parser.Diff([]parser.Transaction{market, coffee}, []parser.Transaction{coffee, market})
+		assert.Equal(t, parser.DiffResult{}, result)
+	})
+}
@@ -0,0 +1,22 @@
+package parser
+
+var LinesToTypedTransactions = linesToTypedTransactions
+
+var ParseTotal = parseTotal
+
+type (
+	PDFWord = pdfWord
+	PDFRow  = pdfRow
+)
+
+var (
+	ScanStatementRows = scanStatementRows
+	PDFWords          = pdfWords
+)
+
+var CheckBalances = checkBalances
+
+var (
+	ScanInvoice = scanInvoice
+	IsInvoice   = isInvoice
+)
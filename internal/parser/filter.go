@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sign selects debits (negative amounts) or credits (positive amounts).
+type Sign string
+
+const (
+	AnySign Sign = ""
+	Debits  Sign = "debits"
+	Credits Sign = "credits"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Filter selects transactions. Zero fields match every transaction.
+type Filter struct {
+	// From and To bound the transaction date, both inclusive.
+	From, To time.Time
+	// Cards are the card endings to keep, e.g. "4321".
+	Cards []string
+	// Payee is matched against the payee anywhere, case insensitive.
+	Payee *regexp.Regexp
+	// MinAmount and MaxAmount bound the absolute amount in cents, inclusive.
+	MinAmount, MaxAmount int64
+	// Installment and Future, when set, keep only transactions whose flag
+	// has the same value.
+	Installment, Future *bool
+	Sign                Sign
+}
+
+// FilterKeys are the names ParseFilter reads, shared by CLI flags and
+// upload form parameters.
+var FilterKeys = []string{"from", "to", "card", "payee", "min", "max", "installments", "future", "sign"}
+
+// ParseFilter builds a Filter from text values, as given by get for each of
+// FilterKeys: dates as YYYY-MM-DD, cards separated by commas, a payee
+// regular expression, amounts as "1.234,56" or "1234.56", "only" or
+// "exclude" for installments and future, and "debits" or "credits" for sign.
+func ParseFilter(get func(key string) string) (Filter, error) {
+	var (
+		f   Filter
+		err error
+	)
+
+	if v := get("from"); v != "" {
+		if f.From, err = time.Parse(time.DateOnly, v); err != nil {
+			return f, fmt.Errorf("%w: from: %w", ErrInvalidFilter, err)
+		}
+	}
+
+	if v := get("to"); v != "" {
+		if f.To, err = time.Parse(time.DateOnly, v); err != nil {
+			return f, fmt.Errorf("%w: to: %w", ErrInvalidFilter, err)
+		}
+	}
+
+	for _, card := range strings.Split(get("card"), coma) {
+		if card = strings.TrimSpace(card); card != "" {
+			f.Cards = append(f.Cards, card)
+		}
+	}
+
+	if v := get("payee"); v != "" {
+		if f.Payee, err = regexp.Compile("(?i)" + v); err != nil {
+			return f, fmt.Errorf("%w: payee: %w", ErrInvalidFilter, err)
+		}
+	}
+
+	if f.MinAmount, err = parseFilterAmount(get("min")); err != nil {
+		return f, fmt.Errorf("%w: min: %w", ErrInvalidFilter, err)
+	}
+
+	if f.MaxAmount, err = parseFilterAmount(get("max")); err != nil {
+		return f, fmt.Errorf("%w: max: %w", ErrInvalidFilter, err)
+	}
+
+	if f.Installment, err = parseFilterFlag(get("installments")); err != nil {
+		return f, fmt.Errorf("%w: installments: %w", ErrInvalidFilter, err)
+	}
+
+	if f.Future, err = parseFilterFlag(get("future")); err != nil {
+		return f, fmt.Errorf("%w: future: %w", ErrInvalidFilter, err)
+	}
+
+	switch f.Sign = Sign(get("sign")); f.Sign {
+	case AnySign, Debits, Credits:
+	default:
+		return f, fmt.Errorf("%w: sign: %q, use debits or credits", ErrInvalidFilter, f.Sign)
+	}
+
+	return f, nil
+}
+
+func parseFilterAmount(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	cents, err := ParseAmount(v)
+	if cents < 0 {
+		cents = -cents
+	}
+
+	return cents, err
+}
+
+func parseFilterFlag(v string) (*bool, error) {
+	var b bool
+
+	switch v {
+	case "":
+		return nil, nil
+	case "only":
+		b = true
+	case "exclude":
+		b = false
+	default:
+		return nil, fmt.Errorf("%q, use only or exclude", v)
+	}
+
+	return &b, nil
+}
+
+// Match reports whether t passes every criteria set in the filter.
+func (f Filter) Match(t Transaction) bool {
+	date := dateOnly(t.Date)
+
+	if !f.From.IsZero() && date.Before(dateOnly(f.From)) {
+		return false
+	}
+
+	if !f.To.IsZero() && date.After(dateOnly(f.To)) {
+		return false
+	}
+
+	if len(f.Cards) > 0 && !slices.Contains(f.Cards, t.Card) {
+		return false
+	}
+
+	if f.Payee != nil && !f.Payee.MatchString(t.Payee) {
+		return false
+	}
+
+	if f.Installment != nil && *f.Installment != t.Installment {
+		return false
+	}
+
+	if f.Future != nil && *f.Future != t.Future {
+		return false
+	}
+
+	return f.matchAmount(t.Amount)
+}
+
+func (f Filter) matchAmount(amount string) bool {
+	if f.MinAmount == 0 && f.MaxAmount == 0 && f.Sign == AnySign {
+		return true
+	}
+
+	cents, err := ParseAmount(amount)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case f.Sign == Debits && cents >= 0, f.Sign == Credits && cents <= 0:
+		return false
+	}
+
+	if cents < 0 {
+		cents = -cents
+	}
+
+	return cents >= f.MinAmount && (f.MaxAmount == 0 || cents <= f.MaxAmount)
+}
+
+// Apply returns the transactions matching the filter, keeping their order.
+func (f Filter) Apply(transactions []Transaction) []Transaction {
+	result := make([]Transaction, 0, len(transactions))
+
+	for _, t := range transactions {
+		if f.Match(t) {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
+
+// dateOnly drops the time and location, so transactions parsed in
+// different time zones still compare by calendar day.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
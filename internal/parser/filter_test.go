@@ -0,0 +1,121 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Date: time.Date(2026, 2, 28, 0, 0, 0, 0, time.Local), Payee: "FEB"},
+		{Date: time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), Payee: "MAR 1"},
+		{Date: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), Payee: "MAR 31"},
+		{Date: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Payee: "APR"},
+	}
+
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	endOfMarch := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter parser.Filter
+		payees []string
+	}{
+		{"zero filter", parser.Filter{}, []string{"FEB", "MAR 1", "MAR 31", "APR"}},
+		{"from", parser.Filter{From: march}, []string{"MAR 1", "MAR 31", "APR"}},
+		{"to", parser.Filter{To: endOfMarch}, []string{"FEB", "MAR 1", "MAR 31"}},
+		{"range", parser.Filter{From: march, To: endOfMarch}, []string{"MAR 1", "MAR 31"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var payees []string
+			for _, tx := range tt.filter.Apply(transactions) {
+				payees = append(payees, tx.Payee)
+			}
+
+			assert.Equal(t, tt.payees, payees)
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "IFD*RESTAURANTE", Card: "1234", Amount: "-45,90"},
+		{Date: date, Payee: "AMAZON BR", Card: "4321", Amount: "-1.500,00", Installment: true},
+		{Date: date.AddDate(0, 1, 0), Payee: "AMAZON BR", Card: "4321", Amount: "-1.500,00", Installment: true, Future: true},
+		{Date: date, Payee: "ESTORNO AMAZON", Card: "4321", Amount: "200,00"},
+		{Date: date, Payee: "SEM VALOR", Card: "1234"},
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []int
+	}{
+		{"no filter", nil, []int{0, 1, 2, 3, 4}},
+		{"card", map[string]string{"card": "1234, 9999"}, []int{0, 4}},
+		{"payee", map[string]string{"payee": "^amazon"}, []int{1, 2}},
+		{"min", map[string]string{"min": "200"}, []int{1, 2, 3}},
+		{"max", map[string]string{"max": "-200,00"}, []int{0, 3}},
+		{"only installments", map[string]string{"installments": "only"}, []int{1, 2}},
+		{"exclude future", map[string]string{"future": "exclude"}, []int{0, 1, 3, 4}},
+		{"debits", map[string]string{"sign": "debits"}, []int{0, 1, 2}},
+		{"credits", map[string]string{"sign": "credits"}, []int{3}},
+		{"combined", map[string]string{"card": "4321", "to": "2026-03-31", "sign": "debits"}, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := parser.ParseFilter(func(key string) string { return tt.values[key] })
+			require.NoError(t, err)
+
+			var got []int
+			for i, tx := range transactions {
+				if filter.Match(tx) {
+					got = append(got, i)
+				}
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for key, value := range map[string]string{
+		"from":         "01/03/2026",
+		"to":           "march",
+		"payee":        "(",
+		"min":          "abc",
+		"max":          "1,2,3",
+		"installments": "yes",
+		"future":       "no",
+		"sign":         "both",
+	} {
+		t.Run("invalid "+key, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parser.ParseFilter(func(k string) string {
+				if k == key {
+					return value
+				}
+				return ""
+			})
+			assert.ErrorIs(t, err, parser.ErrInvalidFilter)
+			assert.ErrorContains(t, err, key)
+		})
+	}
+}
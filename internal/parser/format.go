@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"git.home/c6bank-transactions/internal/ledger"
+	"git.home/c6bank-transactions/internal/ofx"
+	"git.home/c6bank-transactions/internal/qif"
+)
+
+// Format is an output format for Export.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatJSON   Format = "json"
+	FormatQIF    Format = "qif"
+	FormatOFX    Format = "ofx"
+	FormatLedger Format = "ledger"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown output format")
+
+	Formats = []Format{FormatCSV, FormatJSON, FormatQIF, FormatOFX, FormatLedger}
+)
+
+// Export renders transactions in format. qtype tells which kind of account
+// (bank or credit card) the transactions belong to. Transactions without an
+// ID get one from AssignIDs.
+//
+// WithFuture(FutureExclude) leaves projected installments out and
+// WithFuture(FutureMark) flags them: uncleared in QIF (as always), a
+// Scheduled column in CSV and a "[Agendada]" memo prefix in OFX and ledger.
+// Separating them is up to the caller, see SeparateFuture. WithBalances
+// adds the statement balances to QIF and OFX and WithCSVColumns adds the ID
+// and Category columns to CSV.
+func Export(format Format, qtype qif.QIFType, transactions []Transaction, opts ...Option) (io.Reader, error) {
+	o := newOptions(opts)
+
+	if o.future == FutureExclude {
+		transactions, _ = SeparateFuture(transactions)
+	}
+
+	transactions = slices.Clone(transactions)
+	AssignIDs(transactions)
+
+	switch format {
+	case FormatCSV:
+		return transactionsToCSV(transactions, o.future == FutureMark, o.csvColumns)
+	case FormatJSON:
+		return TransactionsToJSON(transactions)
+	case FormatQIF:
+		return qif.Parse(qtype, append(o.openingQIF(transactions), transactionsToQIF(transactions)...))
+	case FormatOFX:
+		return transactionsToOFX(qtype, transactions, o)
+	case FormatLedger:
+		return transactionsToLedger(qtype, transactions, o.future)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+func TransactionsToJSON(transactions []Transaction) (io.Reader, error) {
+	if transactions == nil {
+		transactions = []Transaction{}
+	}
+
+	buf := new(bytes.Buffer)
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(transactions); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+func transactionsToOFX(qtype qif.QIFType, transactions []Transaction, o options) (io.Reader, error) {
+	atype := ofx.CreditCardType
+	if qtype == qif.BankType {
+		atype = ofx.BankType
+	}
+
+	txs := make([]ofx.Transaction, 0, len(transactions))
+
+	for _, t := range transactions {
+		cents, err := ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		ttype := ofx.Credit
+		if cents < 0 {
+			ttype = ofx.Debit
+		}
+
+		if hint := kindHints[t.Kind].ofx; hint != "" {
+			ttype = hint
+		}
+
+		if t.Transfer != "" {
+			ttype = ofx.Xfer
+		}
+
+		txs = append(txs, ofx.Transaction{
+			ID:     t.ID,
+			Type:   ttype,
+			Date:   t.Date.Format(ofx.DateFormat),
+			Amount: FormatCents(cents),
+			Name:   t.Payee,
+			Memo:   t.markedMemo(o.future),
+		})
+	}
+
+	if o.closing != nil {
+		balance := ofx.Balance{Amount: FormatCents(o.closing.Cents)}
+		if !o.closing.Date.IsZero() {
+			balance.Date = o.closing.Date.Format(ofx.DateFormat)
+		}
+
+		return ofx.ParseWithBalance(atype, txs, balance)
+	}
+
+	return ofx.Parse(atype, txs)
+}
+
+func transactionsToLedger(qtype qif.QIFType, transactions []Transaction, future FutureMode) (io.Reader, error) {
+	account := ledger.CardAccount
+	if qtype == qif.BankType {
+		account = ledger.BankAccount
+	}
+
+	txs := make([]ledger.Transaction, 0, len(transactions))
+	ids := make(map[string]bool, len(transactions))
+
+	for _, t := range transactions {
+		ids[t.ID] = true
+	}
+
+	for _, t := range transactions {
+		cents, err := ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		other := kindHints[t.Kind].ledger
+
+		// a transfer posts to both accounts, so only the side of the
+		// output's account is written when both are there
+		if t.Transfer != "" {
+			other = ledger.BankAccount
+			if t.Source == SourceStatement {
+				other = ledger.CardAccount
+			}
+
+			if other == account && ids[t.TransferID] {
+				continue
+			}
+		}
+
+		txs = append(txs, ledger.Transaction{
+			Date:    t.Date.Format(ledger.DateFormat),
+			Payee:   t.Payee,
+			Memo:    t.markedMemo(future),
+			Amount:  FormatCents(cents),
+			Account: other,
+		})
+	}
+
+	return ledger.Parse(account, txs)
+}
+
+func transactionsToQIF(transactions []Transaction) []qif.Transaction {
+	qt := make([]qif.Transaction, 0, len(transactions))
+
+	for _, t := range transactions {
+		qt = append(qt, qif.Transaction{
+			ID:       t.ID,
+			Date:     t.Date.Format(dateFormat),
+			Payee:    t.Payee,
+			Memo:     t.Memo,
+			Amount:   t.Amount,
+			Category: t.categoryHint(),
+			Cleared:  t.cleared(),
+			Splits:   splitsToQIF(t.Splits),
+		})
+	}
+
+	return qt
+}
+
+func splitsToQIF(splits []Split) []qif.Split {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	qs := make([]qif.Split, 0, len(splits))
+	for _, s := range splits {
+		qs = append(qs, qif.Split{Category: s.Category, Memo: s.Memo, Amount: s.Amount})
+	}
+
+	return qs
+}
+
+// cleared tells posted transactions apart from the ones still processing or
+// projected from an installment plan.
+func (t Transaction) cleared() qif.ClearedStatus {
+	if t.Processing || t.Future {
+		return qif.Uncleared
+	}
+
+	return qif.Cleared
+}
+
+// openingQIF is the transaction setting the account's opening balance, dated
+// on the balance or the first transaction, when WithBalances was given.
+func (o options) openingQIF(transactions []Transaction) []qif.Transaction {
+	if o.opening == nil {
+		return nil
+	}
+
+	date := o.opening.Date
+	if date.IsZero() {
+		for i, t := range transactions {
+			if i == 0 || t.Date.Before(date) {
+				date = t.Date
+			}
+		}
+	}
+
+	return []qif.Transaction{{
+		Date:     date.Format(dateFormat),
+		Payee:    openingBalancePayee,
+		Amount:   formatBRL(o.opening.Cents),
+		Category: "[" + bankAccountName + "]",
+		Cleared:  qif.Reconciled,
+	}}
+}
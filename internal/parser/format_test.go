@@ -0,0 +1,81 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Payee: "MERCADO EXTRA", Memo: "1234 01/2026", Amount: "-1.167,91", Category: "Supermercado"},
+		{Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Payee: "ESTORNO", Amount: "10,00", Processing: true},
+	}
+
+	tests := []struct {
+		format parser.Format
+		qtype  qif.QIFType
+		want   []string
+	}{
+		{parser.FormatCSV, qif.CreditCardType, []string{`01/01/2026,MERCADO EXTRA,1234 01/2026,"-1.167,91"`}},
+		{parser.FormatQIF, qif.BankType, []string{"!Type:Bank", "T-1.167,91\nC*\nM1234 01/2026\nLSupermercado\n^", "PESTORNO\nT10,00\n^"}},
+		{parser.FormatOFX, qif.BankType, []string{"<BANKMSGSRSV1>", "<TRNTYPE>DEBIT\n<DTPOSTED>20260101\n<TRNAMT>-1167.91", "<TRNTYPE>CREDIT"}},
+		{parser.FormatOFX, qif.CreditCardType, []string{"<CREDITCARDMSGSRSV1>", "<DTSTART>20260101\n<DTEND>20260102"}},
+		{parser.FormatLedger, qif.CreditCardType, []string{"Liabilities:C6 Bank:Credit Card  BRL -1167.91\n    Expenses:Unknown", "BRL 10.00\n    Income:Unknown"}},
+		{parser.FormatLedger, qif.BankType, []string{"Assets:C6 Bank:Checking"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format)+string(tt.qtype), func(t *testing.T) {
+			t.Parallel()
+
+			r, err := parser.Export(tt.format, tt.qtype, transactions)
+			require.NoError(t, err)
+
+			output, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			for _, want := range tt.want {
+				assert.Contains(t, string(output), want)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := parser.Export(parser.FormatJSON, qif.CreditCardType, transactions)
+		require.NoError(t, err)
+
+		want := slices.Clone(transactions)
+		parser.AssignIDs(want)
+
+		var decoded []parser.Transaction
+		require.NoError(t, json.NewDecoder(r).Decode(&decoded))
+		assert.Equal(t, want, decoded)
+		assert.Empty(t, transactions[0].ID, "Export doesn't change its input")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.Export("xlsx", qif.CreditCardType, transactions)
+		assert.ErrorIs(t, err, parser.ErrUnknownFormat)
+	})
+
+	t.Run("invalid amount", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parser.Export(parser.FormatOFX, qif.CreditCardType, []parser.Transaction{{Amount: "?"}})
+		assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+	})
+}
@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FutureMode tells how outputs handle the installments projected from an
+// installment plan (Future transactions), which finance apps would count
+// again when the next invoice bills them.
+type FutureMode string
+
+const (
+	FutureKeep     FutureMode = ""         // output them as the other transactions
+	FutureExclude  FutureMode = "exclude"  // leave them out
+	FutureMark     FutureMode = "mark"     // flag them as scheduled, see Export
+	FutureSeparate FutureMode = "separate" // write them to a separate "-scheduled" file
+)
+
+// scheduledMemo prefixes the memo of projected installments in formats
+// without a field to flag them.
+const scheduledMemo = "[Agendada] "
+
+var (
+	ErrInvalidFutureMode = errors.New("invalid future mode")
+
+	FutureModes = []FutureMode{FutureExclude, FutureMark, FutureSeparate}
+)
+
+// ParseFutureMode validates s, an empty string meaning FutureKeep.
+func ParseFutureMode(s string) (FutureMode, error) {
+	mode := FutureMode(s)
+	if mode != FutureKeep && !slices.Contains(FutureModes, mode) {
+		return FutureKeep, fmt.Errorf("%w %q, use exclude, mark or separate", ErrInvalidFutureMode, s)
+	}
+
+	return mode, nil
+}
+
+// SeparateFuture splits transactions into the posted and the projected ones,
+// keeping their order.
+func SeparateFuture(transactions []Transaction) (posted, scheduled []Transaction) {
+	for _, t := range transactions {
+		if t.Future {
+			scheduled = append(scheduled, t)
+		} else {
+			posted = append(posted, t)
+		}
+	}
+
+	return posted, scheduled
+}
+
+// ScheduledName names the file of the projected installments after the
+// output file: "out.qif" becomes "out-scheduled.qif".
+func ScheduledName(name string) string {
+	ext := filepath.Ext(name)
+
+	return strings.TrimSuffix(name, ext) + "-scheduled" + ext
+}
+
+// markedMemo is the memo of t in formats flagging projected installments in
+// the memo itself.
+func (t Transaction) markedMemo(mode FutureMode) string {
+	if mode == FutureMark && t.Future {
+		return scheduledMemo + t.Memo
+	}
+
+	return t.Memo
+}
+
+// unmarkMemo reverses markedMemo, telling whether the memo was marked.
+func unmarkMemo(memo string) (string, bool) {
+	return strings.CutPrefix(memo, scheduledMemo)
+}
@@ -0,0 +1,105 @@
+package parser_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFutureMode(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "exclude", "mark", "separate"} {
+		mode, err := parser.ParseFutureMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, parser.FutureMode(s), mode)
+	}
+
+	_, err := parser.ParseFutureMode("hide")
+	require.ErrorIs(t, err, parser.ErrInvalidFutureMode)
+}
+
+func TestSeparateFuture(t *testing.T) {
+	t.Parallel()
+
+	posted, scheduled := parser.SeparateFuture([]parser.Transaction{
+		{Payee: "A"}, {Payee: "B", Future: true}, {Payee: "C"},
+	})
+
+	assert.Equal(t, []parser.Transaction{{Payee: "A"}, {Payee: "C"}}, posted)
+	assert.Equal(t, []parser.Transaction{{Payee: "B", Future: true}}, scheduled)
+	assert.Equal(t, "out-scheduled.qif", parser.ScheduledName("out.qif"))
+}
+
+func TestExport_Future(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{ID: "1", Date: date, Payee: "LOJA", Memo: "1/2 1234 03/2026", Amount: "-10,00", Installment: true},
+		{ID: "2", Date: date.AddDate(0, 1, 0), Payee: "LOJA", Memo: "2/2 1234 04/2026", Amount: "-10,00", Installment: true, Future: true},
+	}
+
+	export := func(t *testing.T, format parser.Format, mode parser.FutureMode) string {
+		t.Helper()
+
+		r, err := parser.Export(format, qif.CreditCardType, transactions, parser.WithFuture(mode))
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	t.Run("exclude", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Date,Payee,Memo,Value\n01/03/2026,LOJA,1/2 1234 03/2026,\"-10,00\"\n", export(t, parser.FormatCSV, parser.FutureExclude))
+	})
+
+	t.Run("mark CSV", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "Date,Payee,Memo,Value,Scheduled\n"+
+			"01/03/2026,LOJA,1/2 1234 03/2026,\"-10,00\",false\n"+
+			"01/04/2026,LOJA,2/2 1234 04/2026,\"-10,00\",true\n", export(t, parser.FormatCSV, parser.FutureMark))
+	})
+
+	t.Run("mark OFX", func(t *testing.T) {
+		t.Parallel()
+
+		out := export(t, parser.FormatOFX, parser.FutureMark)
+		assert.Contains(t, out, "<MEMO>1/2 1234 03/2026")
+		assert.Contains(t, out, "<MEMO>[Agendada] 2/2 1234 04/2026")
+		assert.NotContains(t, export(t, parser.FormatOFX, parser.FutureKeep), "[Agendada]")
+	})
+
+	t.Run("read back", func(t *testing.T) {
+		t.Parallel()
+
+		for _, format := range []parser.Format{parser.FormatCSV, parser.FormatOFX} {
+			r, err := parser.Export(format, qif.CreditCardType, transactions, parser.WithFuture(parser.FutureMark))
+			require.NoError(t, err)
+
+			read := parser.ReadCSV
+			if format == parser.FormatOFX {
+				read = parser.ReadOFX
+			}
+
+			got, err := read(r)
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+
+			assert.False(t, got[0].Future, format)
+			assert.True(t, got[1].Future, format)
+			assert.Equal(t, "2/2 1234 04/2026", got[1].Memo, format)
+		}
+	})
+}
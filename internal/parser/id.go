@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/fasthash/fnv1a"
+)
+
+// AssignIDs gives every transaction without an ID a stable one, so finance
+// apps recognize it when it is imported again. The ID is the decimal FNV-1a
+// hash of
+//
+//	source|card|date|amount|installment|ordinal
+//
+// where date is YYYYMMDD, amount is in cents, installment is the "2/10"
+// prefix of installment memos and ordinal counts the earlier transactions
+// with the same fields, so two identical coffees on the same day get
+// different IDs. Payee and memo are left out, so cleaning them up keeps the
+// ID, and a projected installment gets the same ID as the one billed later.
+func AssignIDs(transactions []Transaction) {
+	ordinals := make(map[string]int)
+
+	for i, t := range transactions {
+		if t.ID != "" {
+			continue
+		}
+
+		key := idKey(t)
+		ordinal := ordinals[key]
+		ordinals[key]++
+
+		transactions[i].ID = strconv.FormatUint(
+			fnv1a.HashString64(key+"|"+strconv.Itoa(ordinal)), 10,
+		)
+	}
+}
+
+func idKey(t Transaction) string {
+	amount := t.Amount
+	if cents, err := ParseAmount(amount); err == nil {
+		amount = strconv.FormatInt(cents, 10)
+	}
+
+	installment := strings.TrimSpace(regexMemoInstallment.FindString(t.Memo))
+
+	return strings.Join([]string{
+		string(t.Source), t.Card, t.Date.Format("20060102"), amount, installment,
+	}, "|")
+}
@@ -0,0 +1,42 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignIDs(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "CAFE", Amount: "-5,00", Card: "1234", Source: parser.SourceInvoice},
+		{Date: date, Payee: "CAFE", Amount: "-5,00", Card: "1234", Source: parser.SourceInvoice},
+		{Date: date, Payee: "Café da Esquina", Amount: "-5,00", Card: "1234", Source: parser.SourceInvoice, Memo: "cleaned up"},
+		{Date: date, Payee: "LOJA", Amount: "-50,00", Card: "1234", Source: parser.SourceInvoice, Memo: "2/3 1234 04/2026", Future: true},
+		{Date: date, Payee: "LOJA", Amount: "-50,00", Card: "1234", Source: parser.SourceInvoice, Memo: "3/3 1234 05/2026", Future: true},
+		{ID: "kept", Date: date, Payee: "CAFE", Amount: "-5,00"},
+	}
+
+	parser.AssignIDs(transactions)
+
+	assert.NotEqual(t, transactions[0].ID, transactions[1].ID, "identical transactions get an ordinal")
+	assert.NotEqual(t, transactions[3].ID, transactions[4].ID, "installments differ by index")
+	assert.Equal(t, "kept", transactions[5].ID)
+
+	// the same invoice read again after a payee cleanup, and the second
+	// installment billed in the next invoice
+	again := []parser.Transaction{
+		{Date: date, Payee: "Café", Amount: "-5.00", Card: "1234", Source: parser.SourceInvoice},
+		{Date: date, Payee: "LOJA", Amount: "-50,00", Card: "1234", Source: parser.SourceInvoice, Memo: "2/3 1234 04/2026"},
+	}
+
+	parser.AssignIDs(again)
+
+	assert.Equal(t, transactions[0].ID, again[0].ID)
+	assert.Equal(t, transactions[3].ID, again[1].ID)
+}
@@ -2,12 +2,15 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"git.home/c6bank-transactions/internal/image"
@@ -20,6 +23,7 @@ import (
 const (
 	space            = " "
 	coma             = ","
+	lf               = "\n"
 	lfRune           = '\n'
 	processingText   = "Em processamento"
 	installmentsText = "Parcela"
@@ -46,28 +50,85 @@ var (
 	}
 )
 
-func ScanImage(file io.ReadSeeker, includeProcessing bool) ([]Transaction, error) {
+func ScanImage(file io.ReadSeeker, includeProcessing bool, opts ...Option) ([]Transaction, error) {
+	transactions, _, err := scanImage(file, includeProcessing, opts...)
+
+	return transactions, err
+}
+
+// scanImage also returns the invoice total when the month region shows it.
+func scanImage(file io.ReadSeeker, includeProcessing bool, opts ...Option) ([]Transaction, []InvoiceTotal, error) {
 	cropped, reference, err := image.Crop(file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	text, err := ocr.Parse(cropped)
+	text, refText, err := parseBoth(newOptions(opts).cache, cropped, reference)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	refText, err := ocr.Parse(reference)
+	header, err := io.ReadAll(refText)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	month, err := ParseRef(refText)
+	month, err := ParseRef(bytes.NewReader(header))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	transactions, err := ScanImageLines(Time{}, text, month, includeProcessing)
+
+	return transactions, parseTotal(month, string(header)), err
+}
+
+// readScreenshot scans a screenshot as ParseFile and Parse read it: signed
+// as in the invoice CSV, where purchases are debits, although the app shows
+// them as positive values.
+func readScreenshot(file io.ReadSeeker, includeProcessing bool, opts ...Option) ([]Transaction, []InvoiceTotal, error) {
+	transactions, totals, err := scanImage(file, includeProcessing, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range transactions {
+		if transactions[i].Amount != "" {
+			fixValue(&transactions[i].Amount)
+		}
 	}
 
-	return ScanImageLines(Time{}, text, month, includeProcessing)
+	return transactions, totals, nil
+}
+
+// parseBoth runs OCR on the transactions and month regions concurrently,
+// as each one is a separate Tesseract process.
+func parseBoth(cache ocr.Cache, cropped, reference io.Reader) (io.Reader, io.Reader, error) {
+	var (
+		wg              sync.WaitGroup
+		text, refText   io.Reader
+		textErr, refErr error
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		text, textErr = ocr.Cached(cache, cropped)
+	}()
+
+	go func() {
+		defer wg.Done()
+		refText, refErr = ocr.Cached(cache, reference)
+	}()
+
+	wg.Wait()
+
+	if err := errors.Join(textErr, refErr); err != nil {
+		return nil, nil, err
+	}
+
+	return text, refText, nil
 }
 
 func ScanImageLines(ct CurrentTime, text io.Reader, ref time.Time, includeProcessing bool) ([]Transaction, error) {
@@ -95,7 +156,7 @@ func ScanImageLines(ct CurrentTime, text io.Reader, ref time.Time, includeProces
 		}
 
 		if len(current) > 0 && regexDate.MatchString(line) {
-			if transaction := parseTransaction(ct, current, refText, includeProcessing); transaction != empty {
+			if transaction := parseTransaction(ct, current, refText, includeProcessing); !transaction.Date.IsZero() {
 				transactions = append(transactions, transaction)
 			}
 
@@ -105,7 +166,7 @@ func ScanImageLines(ct CurrentTime, text io.Reader, ref time.Time, includeProces
 		current += line
 	}
 
-	if transaction := parseTransaction(ct, current, refText, includeProcessing); transaction != empty {
+	if transaction := parseTransaction(ct, current, refText, includeProcessing); !transaction.Date.IsZero() {
 		transactions = append(transactions, transaction)
 	}
 
@@ -145,6 +206,7 @@ func parseInstallments(ts []Transaction, refTime time.Time) ([]Transaction, erro
 				Payee:       t.Payee,
 				Memo:        fmt.Sprintf("%d/%d %s %s", current+i, total, memo, referenceDate.Format(refFormat)),
 				Amount:      t.Amount,
+				Card:        t.Card,
 				Installment: true,
 				Future:      true,
 			})
@@ -172,18 +234,19 @@ func parseInstannmentText(text string) (int, int, string, error) {
 }
 
 func parseTransaction(ct CurrentTime, line, ref string, includeProcessing bool) Transaction {
+	var transaction Transaction
+
 	if strings.Contains(line, processingText) {
 		if !includeProcessing {
 			return empty
 		}
 
 		line = strings.ReplaceAll(line, processingText, "")
+		transaction.Processing = true
 	}
 
 	// fmt.Println("line:", line)
 
-	var transaction Transaction
-
 	// installments
 
 	if strings.Contains(line, installmentsText) {
@@ -210,7 +273,8 @@ func parseTransaction(ct CurrentTime, line, ref string, includeProcessing bool)
 	// card
 
 	if strings.Contains(line, cardText) || strings.Contains(line, cardTextAccent) {
-		transaction.Memo += parseRegex(line, regexCard) + space
+		transaction.Card = parseRegex(line, regexCard)
+		transaction.Memo += transaction.Card + space
 	}
 	line = regexCard.ReplaceAllString(line, "")
 
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"git.home/c6bank-transactions/internal/image"
 	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/parser/ocr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -85,9 +88,36 @@ func TestScanImageLines(t *testing.T) {
 
 		assert.Len(t, lines, 7)
 		assert.Equal(t, lines[0].Payee, "NOME DO LUGAR")
+		assert.True(t, slices.ContainsFunc(lines, func(l parser.Transaction) bool { return l.Processing }))
 	})
 }
 
+func TestParseFile_Screenshot(t *testing.T) {
+	t.Parallel()
+
+	const path = "testdata/IMG_0420.PNG"
+
+	cache := screenshotCache(t, path, "Fatura de janeiro Aberta R$ 167,91")
+
+	fromFile, err := parser.ParseFile(path, parser.WithOCRCache(cache))
+	require.NoError(t, err)
+
+	require.Len(t, fromFile, 1)
+	assert.Equal(t, "-167,91", fromFile[0].Amount)
+
+	// the server reads uploads on its own path, both must sign them alike
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, _, _, err := parser.Parse("IMG_0420.PNG", f, 0, "", false, parser.WithOCRCache(cache))
+	require.NoError(t, err)
+
+	fromUpload, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(fromUpload), `"-167,91"`)
+}
+
 func TestParse(t *testing.T) {
 	t.Parallel()
 
@@ -175,3 +205,31 @@ func transactionText(t *testing.T) io.Reader {
 
 	return bytes.NewBuffer(replaced)
 }
+
+// screenshotCache caches the OCR output of the screenshot at path, so it is
+// parsed without Tesseract: a purchase of R$ 167,91 made today and month as
+// the invoice month region.
+func screenshotCache(t *testing.T, path, month string) ocr.Cache {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	cropped, reference, err := image.Crop(f)
+	require.NoError(t, err)
+
+	today := time.Now()
+	text := fmt.Sprintf("%02d/%02d\n\nCOMPRA A VISTA R$ 167,91\nCartao final 4321\n", today.Day(), today.Month())
+
+	cache := ocr.NewLRU(2)
+
+	for r, output := range map[io.Reader]string{cropped: text, reference: month} {
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		cache.Put(ocr.Key(b), []byte(output))
+	}
+
+	return cache
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrNoDueDate = errors.New("invoice PDF without a due date")
+
+var (
+	regexDueDate      = regexp.MustCompile(`(?i)vencimento\D*?(\d{2}/\d{2}/\d{4})`)
+	regexInvoiceTotal = regexp.MustCompile(`(?i)total (?:a pagar|da fatura)\D*?(\d{1,3}(?:\.\d{3})*,\d{2})`)
+	regexInvoiceCard  = regexp.MustCompile(`(?i)\bfinal\s+(\d{4})\b(?:\s*-\s*(.+))?$`)
+	// 0    1    2           3
+	// line date description value
+	regexInvoiceRow     = regexp.MustCompile(`^(\d{2}/\d{2}(?:/\d{4})?|\d{2} [A-Za-z]{3})\s+(.*?)\s*((?:R\$\s*)?-?\s*\d{1,3}(?:\.\d{3})*,\d{2})?$`)
+	regexInvoiceParcela = regexp.MustCompile(`(?i)\s*-?\s*parcela\s+(\d+)\s*/\s*(\d+)\s*`)
+	regexInvoiceForeign = regexp.MustCompile(`(?i)^(?:US\$|USD)\s*([\d.]+,\d{2}).*?(?:cota[cç][aã]o|d[oó]lar)\D*([\d.]+,\d{2,4})`)
+)
+
+var invoiceMonths = map[string]string{
+	"jan": "01", "fev": "02", "mar": "03", "abr": "04", "mai": "05", "jun": "06",
+	"jul": "07", "ago": "08", "set": "09", "out": "10", "nov": "11", "dez": "12",
+}
+
+// isInvoice tells a card invoice PDF, which has a due date and a total to
+// pay, from an account statement.
+func isInvoice(rows []pdfRow) bool {
+	for _, row := range rows {
+		text := NormalizePayee(row.text())
+		if strings.Contains(text, "TOTAL A PAGAR") || strings.Contains(text, "TOTAL DA FATURA") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanInvoice reads the card invoice PDF into the same transactions as its
+// CSV: every row becomes a CSV record, the card and cardholder coming from
+// the section it's in ("C6 Carbon Final 1234 - FULANO") and the installment
+// from its description ("LOJA - Parcela 2/10"). Dates without year are in
+// the year before the due date when their month is after it. Categories
+// aren't printed, so they're left empty.
+func scanInvoice(rows []pdfRow) (Statement, error) {
+	var (
+		due     time.Time
+		totals  []InvoiceTotal
+		records [][]string
+		skipped []SkippedRow
+		card    string
+		name    string
+	)
+
+	for _, row := range rows {
+		text := row.text()
+
+		if m := regexDueDate.FindStringSubmatch(text); m != nil && due.IsZero() {
+			due, _ = time.Parse(dateFormat, m[1])
+		}
+
+		if m := regexInvoiceTotal.FindStringSubmatch(text); m != nil && totals == nil {
+			if cents, err := ParseAmount(m[1]); err == nil {
+				totals = []InvoiceTotal{{Cents: cents}}
+			}
+		}
+	}
+
+	if due.IsZero() {
+		return Statement{}, ErrNoDueDate
+	}
+
+	reference := time.Date(due.Year(), due.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := range totals {
+		totals[i].Month = reference
+	}
+
+	for _, row := range rows {
+		text := row.text()
+
+		m := regexInvoiceRow.FindStringSubmatch(text)
+		if m == nil {
+			if c := regexInvoiceCard.FindStringSubmatch(text); c != nil {
+				card, name = c[1], strings.TrimSpace(c[2])
+			} else if f := regexInvoiceForeign.FindStringSubmatch(text); f != nil && len(records) > 0 {
+				records[len(records)-1][6], records[len(records)-1][7] = f[1], f[2]
+			}
+
+			continue
+		}
+
+		date, ok := invoiceDate(m[1], due)
+		payee, installment := invoiceInstallment(m[2])
+		value := strings.ReplaceAll(strings.TrimSpace(strings.TrimPrefix(m[3], "R$")), " ", "")
+		line := Line{m[1], payee, installment, value}
+
+		var reason string
+
+		switch {
+		case !ok:
+			reason = fmt.Sprintf("invalid date %q", m[1])
+		case payee == "":
+			reason = "no description"
+		case value == "":
+			reason = "no amount"
+		}
+
+		if reason != "" {
+			skipped = append(skipped, SkippedRow{
+				Row:    len(records) + len(skipped) + 1,
+				Line:   line,
+				Reason: fmt.Sprintf("page %d: %s", row.Page, reason),
+			})
+
+			continue
+		}
+
+		records = append(records, []string{date, name, card, "", payee, installment, "", "", value})
+	}
+
+	var transactions []Transaction
+
+	for i, record := range records {
+		appendInvoiceRecord(due, i+1, record, &transactions, &skipped)
+	}
+
+	withSource(transactions, SourceInvoice)
+	AssignIDs(transactions)
+
+	return Statement{Kind: PDFInvoice, Transactions: transactions, Totals: totals, Skipped: skipped}, nil
+}
+
+// invoiceDate formats "15/03", "15 mar" or "15/03/2026" as a full date, the
+// year being the one that puts it up to the due date.
+func invoiceDate(date string, due time.Time) (string, bool) {
+	if day, month, ok := strings.Cut(date, " "); ok {
+		month, ok = invoiceMonths[strings.ToLower(month)]
+		if !ok {
+			return "", false
+		}
+
+		date = day + "/" + month
+	}
+
+	if len(date) == len(dateFormat) {
+		_, err := time.Parse(dateFormat, date)
+
+		return date, err == nil
+	}
+
+	t, err := time.Parse(dateFormat, fmt.Sprintf("%s/%d", date, due.Year()))
+	if err != nil {
+		return "", false
+	}
+
+	if t.After(due) {
+		t = t.AddDate(-1, 0, 0)
+	}
+
+	return t.Format(dateFormat), true
+}
+
+// invoiceInstallment cuts the installment out of the description, returning
+// it as in the CSV: "2/10", or "Única" when there's none.
+func invoiceInstallment(description string) (string, string) {
+	m := regexInvoiceParcela.FindStringSubmatchIndex(description)
+	if m == nil {
+		return strings.TrimSpace(description), unique
+	}
+
+	payee := strings.TrimSpace(description[:m[0]] + " " + description[m[1]:])
+
+	return payee, description[m[2]:m[3]] + "/" + description[m[4]:m[5]]
+}
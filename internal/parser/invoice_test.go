@@ -0,0 +1,105 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textRow lays out the words of text one after the other.
+func textRow(page int, text string) parser.PDFRow {
+	r := row(page)
+
+	x := 40.0
+	for _, w := range strings.Fields(text) {
+		r.Words = append(r.Words, word(x, w))
+		x += r.Words[len(r.Words)-1].W + 5
+	}
+
+	return r
+}
+
+func invoiceRows(texts ...string) []parser.PDFRow {
+	rows := make([]parser.PDFRow, len(texts))
+	for i, text := range texts {
+		rows[i] = textRow(1, text)
+	}
+
+	return rows
+}
+
+func TestScanInvoice(t *testing.T) {
+	t.Parallel()
+
+	rows := invoiceRows(
+		"Fatura do cartão C6",
+		"Vencimento: 15/03/2026",
+		"Total a pagar R$ 1.234,56",
+		"C6 Carbon Final 1234 - FULANO DE TAL",
+		"10/02 Padaria São João 25,90",
+		"20 jan Loja Eletrônicos - Parcela 1/3 300,00",
+		"05/12 Curso Online - Parcela 4/10 99,90",
+		"C6 Carbon Virtual Final 5678",
+		"01/03 Amazon Web Services 50,00",
+		"USD 10,00 Cotação R$ 5,00",
+		"28/02 Estorno Loja -25,90",
+		"03/03 Tarifa",
+	)
+	require.True(t, parser.IsInvoice(rows))
+
+	invoice, err := parser.ScanInvoice(rows)
+	require.NoError(t, err)
+
+	assert.Equal(t, parser.PDFInvoice, invoice.Kind)
+	assert.Equal(t, []parser.InvoiceTotal{{Month: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Cents: 123456}}, invoice.Totals)
+
+	require.Len(t, invoice.Skipped, 1)
+	assert.Equal(t, "page 1: no amount", invoice.Skipped[0].Reason)
+
+	date := func(day, month, year int) time.Time {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+
+	loja := parser.Transaction{Payee: "Loja Eletrônicos", Amount: "-300,00", Card: "1234", CardName: "FULANO DE TAL", Installment: true, Source: parser.SourceInvoice}
+
+	want := []parser.Transaction{
+		{Date: date(10, 2, 2026), Payee: "Padaria São João", Memo: "1234 03/2026", Amount: "-25,90", Card: "1234", CardName: "FULANO DE TAL", Source: parser.SourceInvoice},
+		withInvoiceInstallment(loja, date(20, 1, 2026), "1/3 1234 03/2026", false),
+		withInvoiceInstallment(loja, date(20, 2, 2026), "2/3 1234 04/2026", true),
+		withInvoiceInstallment(loja, date(20, 3, 2026), "3/3 1234 05/2026", true),
+		{Date: date(5, 3, 2026), Payee: "Curso Online", Memo: "4/10 1234 03/2026", Amount: "-99,90", Card: "1234", CardName: "FULANO DE TAL", Installment: true, Source: parser.SourceInvoice},
+		{Date: date(1, 3, 2026), Payee: "Amazon Web Services", Memo: "5678 03/2026", Amount: "-50,00", Card: "5678", Source: parser.SourceInvoice, ForeignAmount: "10,00", ExchangeRate: "5,00"},
+		{Date: date(28, 2, 2026), Payee: "Estorno Loja", Memo: "5678 03/2026", Amount: "25,90", Card: "5678", Source: parser.SourceInvoice},
+	}
+
+	for i := range invoice.Transactions {
+		assert.NotEmpty(t, invoice.Transactions[i].ID)
+		invoice.Transactions[i].ID = ""
+	}
+
+	assert.Equal(t, want, invoice.Transactions)
+}
+
+func withInvoiceInstallment(t parser.Transaction, date time.Time, memo string, future bool) parser.Transaction {
+	t.Date, t.Memo, t.Future = date, memo, future
+
+	return t
+}
+
+func TestScanInvoice_NoDueDate(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.ScanInvoice(invoiceRows("Total a pagar R$ 10,00", "10/02 Padaria 10,00"))
+	require.ErrorIs(t, err, parser.ErrNoDueDate)
+}
+
+func TestIsInvoice(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, parser.IsInvoice(statementRows("950,00")))
+	assert.True(t, parser.IsInvoice(invoiceRows("TOTAL DA FATURA 10,00")))
+}
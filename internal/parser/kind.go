@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"git.home/c6bank-transactions/internal/ledger"
+	"git.home/c6bank-transactions/internal/ofx"
+)
+
+// Kind is what an account statement transaction is, told from its
+// description.
+type Kind string
+
+const (
+	KindOther          Kind = ""
+	KindPixSent        Kind = "pix_sent"
+	KindPixReceived    Kind = "pix_received"
+	KindTEDSent        Kind = "ted_sent"
+	KindTEDReceived    Kind = "ted_received"
+	KindBoleto         Kind = "boleto"
+	KindDebitCard      Kind = "debit_card"
+	KindInvoicePayment Kind = "invoice_payment"
+	KindInvestment     Kind = "investment" // application
+	KindRedemption     Kind = "redemption"
+	KindFee            Kind = "fee"
+	KindSalary         Kind = "salary"
+)
+
+// kindRules are tried in order on the normalized payee and memo, sign
+// telling whether they apply to credits (1), debits (-1) or both (0).
+var kindRules = []struct {
+	kind  Kind
+	sign  int
+	match *regexp.Regexp
+}{
+	{KindInvoicePayment, -1, regexp.MustCompile(`\b(PAGAMENTO|PAGTO|PGTO) (DE |DA )?FATURA\b|\bFATURA (DO )?CARTAO\b`)},
+	{KindFee, -1, regexp.MustCompile(`\b(TARIFA|IOF|JUROS|ENCARGOS|ANUIDADE|MULTA)\b`)},
+	{KindSalary, 1, regexp.MustCompile(`\b(SALARIO|PROVENTOS|FOLHA( DE)? PAGAMENTO)\b`)},
+	{KindRedemption, 1, regexp.MustCompile(`\bRESGATE\b`)},
+	{KindInvestment, -1, regexp.MustCompile(`\b(APLICACAO|INVESTIMENTO|CDB|TESOURO)\b`)},
+	{KindBoleto, -1, regexp.MustCompile(`\b(BOLETO|PAGTO TITULO|PAGAMENTO (DE )?(TITULO|CONTA)|CONVENIO)\b`)},
+	{KindDebitCard, -1, regexp.MustCompile(`\b(COMPRA|CARTAO (DE )?DEBITO|DEBITO (VISA|MASTERCARD|ELO))\b`)},
+	{KindPixSent, -1, regexp.MustCompile(`\bPIX\b`)},
+	{KindPixReceived, 1, regexp.MustCompile(`\bPIX\b`)},
+	{KindTEDSent, -1, regexp.MustCompile(`\b(TED|DOC|TRANSF|TRANSFERENCIA)\b`)},
+	{KindTEDReceived, 1, regexp.MustCompile(`\b(TED|DOC|TRANSF|TRANSFERENCIA)\b`)},
+}
+
+// kindHints are how every kind shows in the outputs: the QIF and CSV
+// category, the OFX TRNTYPE and the ledger account.
+var kindHints = map[Kind]struct {
+	category string
+	ofx      ofx.TransactionType
+	ledger   string
+}{
+	KindPixSent:        {"PIX enviado", ofx.Xfer, ""},
+	KindPixReceived:    {"PIX recebido", ofx.Xfer, ""},
+	KindTEDSent:        {"TED enviada", ofx.Xfer, ""},
+	KindTEDReceived:    {"TED recebida", ofx.Xfer, ""},
+	KindBoleto:         {"Boleto", ofx.Payment, ""},
+	KindDebitCard:      {"Cartão de débito", ofx.POS, ""},
+	KindInvoicePayment: {"Pagamento de fatura", ofx.Payment, ledger.CardAccount},
+	KindInvestment:     {"Aplicação", ofx.Xfer, ledger.InvestmentAccount},
+	KindRedemption:     {"Resgate", ofx.Xfer, ledger.InvestmentAccount},
+	KindFee:            {"Tarifas", ofx.Fee, ledger.FeesAccount},
+	KindSalary:         {"Salário", ofx.DirectDeposit, ledger.SalaryAccount},
+}
+
+var (
+	regexCPF        = regexp.MustCompile(`[*\d]{3}\.[*\d]{3}\.[*\d]{3}-[*\d]{2}`)
+	regexCNPJ       = regexp.MustCompile(`\d{2}\.\d{3}\.\d{3}/\d{4}-\d{2}`)
+	regexUnmasked   = regexp.MustCompile(`^\d{3}\.(\d{3}\.\d{3})-\d{2}$`)
+	counterpartTrim = " -/:|"
+)
+
+// Category is the category the kind suggests, empty for KindOther.
+func (k Kind) Category() string {
+	return kindHints[k].category
+}
+
+// Classify sets the kind of the account statement transactions, along with
+// the counterpart (the memo of "PIX ENVIADO - FULANO") and its CPF or CNPJ.
+// CPFs are masked as the bank does, "***.456.789-**", when printed in full.
+func Classify(transactions []Transaction) {
+	for i := range transactions {
+		t := &transactions[i]
+
+		cents, err := ParseAmount(t.Amount)
+		if err != nil {
+			continue
+		}
+
+		t.Kind = classify(NormalizePayee(t.Payee+" "+t.Memo), cents)
+		if t.Kind == KindOther {
+			continue
+		}
+
+		t.Counterpart, t.Document = counterpart(t.Memo)
+	}
+}
+
+func classify(text string, cents int64) Kind {
+	for _, rule := range kindRules {
+		if (rule.sign > 0 && cents < 0) || (rule.sign < 0 && cents > 0) {
+			continue
+		}
+
+		if rule.match.MatchString(text) {
+			return rule.kind
+		}
+	}
+
+	return KindOther
+}
+
+// counterpart cuts the CPF or CNPJ out of memo, masking full CPFs.
+func counterpart(memo string) (name, document string) {
+	document = regexCNPJ.FindString(memo)
+	if document == "" {
+		document = regexCPF.FindString(memo)
+	}
+
+	if document != "" {
+		memo = strings.Replace(memo, document, " ", 1)
+	}
+
+	if m := regexUnmasked.FindStringSubmatch(document); m != nil {
+		document = "***." + m[1] + "-**"
+	}
+
+	return strings.Join(strings.Fields(strings.Trim(memo, counterpartTrim)), " "), document
+}
+
+// categoryHint is the QIF transfer to the other account of a transfer, or
+// the category of t, or the one its kind suggests.
+func (t Transaction) categoryHint() string {
+	if t.Transfer != "" {
+		return "[" + t.Transfer + "]"
+	}
+
+	if t.Category != "" {
+		return t.Category
+	}
+
+	return t.Kind.Category()
+}
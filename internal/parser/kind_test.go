@@ -0,0 +1,90 @@
+package parser_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		payee, memo, amount string
+		kind                parser.Kind
+		counterpart         string
+		document            string
+	}{
+		{"PIX ENVIADO", "Fulano de Tal 123.456.789-01", "-50,00", parser.KindPixSent, "Fulano de Tal", "***.456.789-**"},
+		{"Pix recebido", "Ciclano - ***.654.321-**", "80,00", parser.KindPixReceived, "Ciclano", "***.654.321-**"},
+		{"TED ENVIADA", "EMPRESA LTDA 12.345.678/0001-90", "-1.000,00", parser.KindTEDSent, "EMPRESA LTDA", "12.345.678/0001-90"},
+		{"TRANSFERENCIA RECEBIDA", "Beltrano", "200,00", parser.KindTEDReceived, "Beltrano", ""},
+		{"TED RECEBIDA", "SALARIO EMPRESA LTDA", "5.000,00", parser.KindSalary, "SALARIO EMPRESA LTDA", ""},
+		{"PAGTO FATURA", "CARTAO C6", "-1.234,56", parser.KindInvoicePayment, "CARTAO C6", ""},
+		{"PAGAMENTO DE BOLETO", "CONDOMINIO", "-700,00", parser.KindBoleto, "CONDOMINIO", ""},
+		{"COMPRA CARTAO DEBITO", "PADARIA SÃO JOÃO", "-12,50", parser.KindDebitCard, "PADARIA SÃO JOÃO", ""},
+		{"APLICAÇÃO CDB", "", "-500,00", parser.KindInvestment, "", ""},
+		{"RESGATE CDB", "", "510,00", parser.KindRedemption, "", ""},
+		{"TARIFA PACOTE", "", "-19,90", parser.KindFee, "", ""},
+		{"ESTORNO", "LOJA", "10,00", parser.KindOther, "", ""},
+	}
+
+	transactions := make([]parser.Transaction, len(tests))
+	for i, tt := range tests {
+		transactions[i] = parser.Transaction{Payee: tt.payee, Memo: tt.memo, Amount: tt.amount}
+	}
+
+	parser.Classify(transactions)
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.kind, transactions[i].Kind, tt.payee)
+		assert.Equal(t, tt.counterpart, transactions[i].Counterpart, tt.payee)
+		assert.Equal(t, tt.document, transactions[i].Document, tt.payee)
+	}
+}
+
+func TestExport_KindHints(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{ID: "1", Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Payee: "TARIFA PACOTE", Amount: "-19,90", Kind: parser.KindFee},
+		{ID: "2", Date: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Payee: "PIX RECEBIDO", Memo: "Fulano", Amount: "80,00", Kind: parser.KindPixReceived},
+		{ID: "3", Date: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Payee: "ESTORNO", Amount: "10,00"},
+	}
+
+	export := func(format parser.Format, opts ...parser.Option) string {
+		r, err := parser.Export(format, qif.BankType, transactions, opts...)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	out := export(parser.FormatQIF)
+	assert.Contains(t, out, "LTarifas\n")
+	assert.Contains(t, out, "LPIX recebido\n")
+
+	out = export(parser.FormatOFX)
+	assert.Contains(t, out, "<TRNTYPE>FEE\n")
+	assert.Contains(t, out, "<TRNTYPE>XFER\n")
+	assert.Contains(t, out, "<TRNTYPE>CREDIT\n")
+
+	assert.Contains(t, export(parser.FormatLedger), "    Expenses:Bank Fees\n")
+
+	assert.True(t, strings.HasPrefix(export(parser.FormatCSV), "Date,Payee,Memo,Value\n"), "no hints without WithCSVColumns")
+
+	out = export(parser.FormatCSV, parser.WithCSVColumns())
+	assert.True(t, strings.HasPrefix(out, "Date,Payee,Memo,Value,ID,Category\n02/03/2026,TARIFA PACOTE,,\"-19,90\",1,Tarifas\n"), out)
+
+	got, err := parser.ReadCSV(strings.NewReader(out))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Tarifas", "PIX recebido", ""}, []string{got[0].Category, got[1].Category, got[2].Category})
+}
@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Source is where a transaction was read from.
+type Source string
+
+const (
+	SourceInvoice    Source = "invoice"    // C6 card invoice CSV
+	SourceStatement  Source = "statement"  // account statement PDF
+	SourceExport     Source = "export"     // a previous export of this tool
+	SourceScreenshot Source = "screenshot" // OCR of an app screenshot
+)
+
+// fidelity ranks sources, the higher the more trustworthy.
+func (s Source) fidelity() int {
+	switch s {
+	case SourceInvoice, SourceStatement:
+		return 3
+	case SourceExport:
+		return 2
+	case SourceScreenshot:
+		return 1
+	default:
+		return 0
+	}
+}
+
+var (
+	regexNonAlnum        = regexp.MustCompile(`[^A-Z0-9]+`)
+	regexMemoInstallment = regexp.MustCompile(`^(\d+)/(\d+) `)
+
+	accents = strings.NewReplacer(
+		"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A",
+		"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+		"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+		"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ö", "O",
+		"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+		"Ç", "C", "Ñ", "N",
+	)
+
+	// ocrConfusions maps characters Tesseract mixes up to a single one
+	ocrConfusions = strings.NewReplacer(
+		"0", "O", "Q", "O",
+		"1", "I", "L", "I",
+		"5", "S", "8", "B", "2", "Z", "6", "G",
+	)
+)
+
+// NormalizePayee uppercases payee, removing accents, punctuation and repeated
+// spaces, so "Padaria São João*" and "PADARIA SAO JOAO" are equal.
+func NormalizePayee(payee string) string {
+	text := accents.Replace(strings.ToUpper(payee))
+
+	return strings.TrimSpace(regexNonAlnum.ReplaceAllString(text, " "))
+}
+
+// Matcher finds the same transaction read from different sources.
+type Matcher struct {
+	MaxDays int // how many days apart the same transaction can be dated
+}
+
+var DefaultMatcher = Matcher{MaxDays: 2}
+
+// Merge is a transaction dropped by Matcher.Merge in favor of the one kept.
+type Merge struct {
+	Kept    Transaction `json:"kept"`
+	Dropped Transaction `json:"dropped"`
+	Reasons []string    `json:"reasons"`
+}
+
+// Match tells whether a and b are the same transaction, with the reasons
+// when they are. Amounts must be equal; cards and installments must agree
+// when both sides have them; dates and payees may differ slightly.
+func (m Matcher) Match(a, b Transaction) ([]string, bool) {
+	if a.Source == b.Source || a.Future != b.Future {
+		return nil, false
+	}
+
+	ca, errA := ParseAmount(a.Amount)
+	cb, errB := ParseAmount(b.Amount)
+	if errA != nil || errB != nil || ca != cb {
+		return nil, false
+	}
+
+	if a.Card != "" && b.Card != "" && a.Card != b.Card {
+		return nil, false
+	}
+
+	ia := regexMemoInstallment.FindString(a.Memo)
+	ib := regexMemoInstallment.FindString(b.Memo)
+	if ia != "" && ib != "" && ia != ib {
+		return nil, false
+	}
+
+	days := int(dateOnly(a.Date).Sub(dateOnly(b.Date)).Abs() / (24 * time.Hour))
+	if days > m.MaxDays {
+		return nil, false
+	}
+
+	reason, ok := matchPayee(a.Payee, b.Payee)
+	if !ok {
+		return nil, false
+	}
+
+	reasons := []string{"same amount", reason}
+	if days > 0 {
+		reasons = append(reasons, fmt.Sprintf("dates %d day(s) apart", days))
+	}
+
+	return reasons, true
+}
+
+func matchPayee(a, b string) (string, bool) {
+	na, nb := NormalizePayee(a), NormalizePayee(b)
+	if na == nb {
+		if a == b {
+			return "same payee", true
+		}
+
+		return "same payee after normalizing", true
+	}
+
+	ca := ocrConfusions.Replace(strings.ReplaceAll(na, " ", ""))
+	cb := ocrConfusions.Replace(strings.ReplaceAll(nb, " ", ""))
+
+	switch shorter := min(len(ca), len(cb)); {
+	case ca == cb:
+		return "payee matches with OCR confusions", true
+	case shorter >= 5 && (strings.HasPrefix(ca, cb) || strings.HasPrefix(cb, ca)):
+		return "truncated payee", true
+	default:
+		if d := levenshtein(ca, cb); d <= max(1, shorter/6) {
+			return fmt.Sprintf("payee differs by %d character(s)", d), true
+		}
+	}
+
+	return "", false
+}
+
+// Merge drops transactions matching one from another source, keeping the
+// one from the most trustworthy source. Empty card, name and category of
+// the kept transaction are filled from the dropped one.
+func (m Matcher) Merge(transactions []Transaction) ([]Transaction, []Merge) {
+	var (
+		kept   []Transaction
+		merges []Merge
+		// matched[i] has the sources already merged into kept[i], so each
+		// source contributes at most one transaction to it
+		matched []map[Source]bool
+	)
+
+	for _, t := range transactions {
+		i, reasons := -1, []string(nil)
+
+		for j, k := range kept {
+			if matched[j][t.Source] {
+				continue
+			}
+
+			if r, ok := m.Match(k, t); ok {
+				i, reasons = j, r
+				break
+			}
+		}
+
+		if i == -1 {
+			kept = append(kept, t)
+			matched = append(matched, map[Source]bool{t.Source: true})
+
+			continue
+		}
+
+		winner, loser := kept[i], t
+		if t.Source.fidelity() > winner.Source.fidelity() {
+			winner, loser = t, winner
+		}
+
+		winner = fillFrom(winner, loser)
+		kept[i] = winner
+		matched[i][t.Source] = true
+		merges = append(merges, Merge{Kept: winner, Dropped: loser, Reasons: reasons})
+	}
+
+	return kept, merges
+}
+
+func fillFrom(t, other Transaction) Transaction {
+	if t.Card == "" {
+		t.Card = other.Card
+	}
+
+	if t.CardName == "" {
+		t.CardName = other.CardName
+	}
+
+	if t.Category == "" {
+		t.Category = other.Category
+	}
+
+	return t
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,105 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePayee(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "PADARIA SAO JOAO", parser.NormalizePayee("Padaria São  João*"))
+	assert.Equal(t, "IFD RESTAURANTE X", parser.NormalizePayee("IFD*RESTAURANTE X"))
+}
+
+func TestMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	invoice := parser.Transaction{Date: date, Payee: "NOME DO LUGAR", Memo: "1234 03/2026", Amount: "-45,90", Card: "1234", Source: parser.SourceInvoice}
+
+	tests := []struct {
+		name    string
+		other   parser.Transaction
+		want    bool
+		reasons []string
+	}{
+		{
+			name:    "OCR confusions and date offset",
+			other:   parser.Transaction{Date: date.AddDate(0, 0, 1), Payee: "N0ME D0 LUGAR", Memo: "1234 ", Amount: "-45,90", Source: parser.SourceScreenshot},
+			want:    true,
+			reasons: []string{"same amount", "payee matches with OCR confusions", "dates 1 day(s) apart"},
+		},
+		{
+			name:    "truncated payee",
+			other:   parser.Transaction{Date: date, Payee: "NOME DO LUG", Amount: "-45,90", Source: parser.SourceScreenshot},
+			want:    true,
+			reasons: []string{"same amount", "truncated payee"},
+		},
+		{
+			name:    "misread character",
+			other:   parser.Transaction{Date: date, Payee: "NOME DO LUGAP", Amount: "-45,90", Source: parser.SourceScreenshot},
+			want:    true,
+			reasons: []string{"same amount", "payee differs by 1 character(s)"},
+		},
+		{
+			name:  "same source",
+			other: parser.Transaction{Date: date, Payee: "NOME DO LUGAR", Amount: "-45,90", Source: parser.SourceInvoice},
+		},
+		{
+			name:  "different amount",
+			other: parser.Transaction{Date: date, Payee: "NOME DO LUGAR", Amount: "-45,91", Source: parser.SourceScreenshot},
+		},
+		{
+			name:  "different card",
+			other: parser.Transaction{Date: date, Payee: "NOME DO LUGAR", Amount: "-45,90", Card: "4321", Source: parser.SourceScreenshot},
+		},
+		{
+			name:  "too far apart",
+			other: parser.Transaction{Date: date.AddDate(0, 0, 3), Payee: "NOME DO LUGAR", Amount: "-45,90", Source: parser.SourceScreenshot},
+		},
+		{
+			name:  "another payee",
+			other: parser.Transaction{Date: date, Payee: "PADARIA", Amount: "-45,90", Source: parser.SourceScreenshot},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reasons, ok := parser.DefaultMatcher.Match(invoice, tt.other)
+			assert.Equal(t, tt.want, ok)
+			assert.Equal(t, tt.reasons, reasons)
+		})
+	}
+}
+
+func TestMatcher_Merge(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "CAFE", Amount: "-5,00", Source: parser.SourceScreenshot},
+		{Date: date, Payee: "CAFE", Amount: "-5,00", Source: parser.SourceScreenshot, Memo: "second coffee"},
+		{Date: date, Payee: "CAFÉ", Amount: "-5,00", Card: "1234", Category: "Restaurante", Source: parser.SourceInvoice},
+		{Date: date, Payee: "PADARIA", Amount: "-9,00", Source: parser.SourceInvoice},
+	}
+
+	got, merges := parser.DefaultMatcher.Merge(transactions)
+	require.Len(t, got, 3)
+	require.Len(t, merges, 1)
+
+	assert.Equal(t, parser.SourceInvoice, got[0].Source, "the invoice is preferred over the screenshot")
+	assert.Equal(t, "CAFÉ", got[0].Payee)
+	assert.Equal(t, "second coffee", got[1].Memo, "each source merges a single transaction")
+	assert.Equal(t, "PADARIA", got[2].Payee)
+
+	assert.Equal(t, parser.SourceScreenshot, merges[0].Dropped.Source)
+	assert.Equal(t, []string{"same amount", "same payee after normalizing"}, merges[0].Reasons)
+}
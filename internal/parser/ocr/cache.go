@@ -0,0 +1,157 @@
+package ocr
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache stores OCR output by Key.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, text []byte)
+}
+
+// Stats counts cache lookups.
+type Stats struct {
+	Hits, Misses int64
+}
+
+type counter struct {
+	hits, misses atomic.Int64
+}
+
+func (c *counter) count(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// Stats returns how many lookups were answered by the cache so far.
+func (c *counter) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// Key hashes the image bytes together with the Tesseract settings, so
+// changing the settings never returns output produced by the old ones.
+func Key(image []byte) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(args, " ")))
+	h.Write([]byte{0})
+	h.Write(image)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var (
+	_ Cache = (*DirCache)(nil)
+	_ Cache = (*LRU)(nil)
+)
+
+// DirCache keeps one file per key in a local directory, surviving between runs.
+// The directory is only created when the first entry is stored.
+type DirCache struct {
+	counter
+	dir string
+}
+
+func NewDirCache(dir string) *DirCache {
+	return &DirCache{dir: dir}
+}
+
+func (c *DirCache) Get(key string) ([]byte, bool) {
+	text, err := os.ReadFile(c.path(key))
+	c.count(err == nil)
+
+	return text, err == nil
+}
+
+// Put writes through a temporary file so concurrent readers never see a
+// partially written entry. Failures only cost a future cache miss.
+func (c *DirCache) Put(key string, text []byte) {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(text)
+	if cerr := tmp.Close(); err != nil || cerr != nil {
+		return
+	}
+
+	_ = os.Rename(tmp.Name(), c.path(key))
+}
+
+func (c *DirCache) path(key string) string {
+	return filepath.Join(c.dir, key+".txt")
+}
+
+// LRU is an in-memory cache that keeps the size most recently used entries.
+type LRU struct {
+	counter
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	text []byte
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    max(1, size),
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	c.count(ok)
+
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return element.Value.(*lruEntry).text, true
+}
+
+func (c *LRU) Put(key string, text []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).text = text
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, text: text})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
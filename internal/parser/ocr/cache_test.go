@@ -0,0 +1,121 @@
+package ocr_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/parser/ocr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ocr.Key([]byte("image")), ocr.Key([]byte("image")))
+	assert.NotEqual(t, ocr.Key([]byte("image")), ocr.Key([]byte("other")))
+	assert.Len(t, ocr.Key(nil), 64)
+}
+
+func TestDirCache(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "ocr")
+	cache := ocr.NewDirCache(dir)
+
+	_, ok := cache.Get("missing")
+	assert.False(t, ok)
+
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err), "directory is created lazily")
+
+	cache.Put("key", []byte("text"))
+
+	text, ok := cache.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "text", string(text))
+
+	// a new cache on the same directory sees the previous run's entries
+	text, ok = ocr.NewDirCache(dir).Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "text", string(text))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are cleaned up")
+
+	assert.Equal(t, ocr.Stats{Hits: 1, Misses: 1}, cache.Stats())
+}
+
+func TestLRU(t *testing.T) {
+	t.Parallel()
+
+	cache := ocr.NewLRU(2)
+
+	cache.Put("a", []byte("1"))
+	cache.Put("b", []byte("2"))
+
+	_, ok := cache.Get("a") // a is now the most recently used
+	assert.True(t, ok)
+
+	cache.Put("c", []byte("3")) // evicts b
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	text, ok := cache.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, "3", string(text))
+
+	cache.Put("a", []byte("updated"))
+	text, ok = cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "updated", string(text))
+
+	assert.Equal(t, ocr.Stats{Hits: 3, Misses: 1}, cache.Stats())
+}
+
+func TestCached(t *testing.T) {
+	t.Parallel()
+
+	image := []byte("not really an image")
+
+	cache := ocr.NewLRU(1)
+	cache.Put(ocr.Key(image), []byte("cached text"))
+
+	// a hit never runs tesseract
+	reader, err := ocr.Cached(cache, bytes.NewReader(image))
+	require.NoError(t, err)
+
+	text, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "cached text", string(text))
+}
+
+func TestCached_Miss(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath(ocr.TesseractBin); err != nil {
+		t.Skip("missing `tesseract` binary")
+	}
+
+	image, err := os.ReadFile("../../../test/fixtures/cropped.png")
+	require.NoError(t, err)
+
+	cache := ocr.NewLRU(1)
+
+	for range 2 {
+		reader, err := ocr.Cached(cache, bytes.NewReader(image))
+		require.NoError(t, err)
+
+		text, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, parsedText, string(text))
+	}
+
+	assert.Equal(t, ocr.Stats{Hits: 1, Misses: 1}, cache.Stats())
+}
@@ -10,7 +10,12 @@ import (
 
 const TesseractBin = "tesseract"
 
-var ErrOCRParse = errors.New("ocr parse error")
+var (
+	ErrOCRParse = errors.New("ocr parse error")
+
+	// args are the Tesseract settings, also part of every cache key.
+	args = []string{"stdin", "stdout", "--psm", "4", "-l", "por+eng"}
+)
 
 func Parse(file io.Reader) (io.Reader, error) {
 	var (
@@ -18,7 +23,7 @@ func Parse(file io.Reader) (io.Reader, error) {
 		ocrError  bytes.Buffer
 	)
 
-	cmd := exec.Command(TesseractBin, "stdin", "stdout", "--psm", "4", "-l", "por+eng")
+	cmd := exec.Command(TesseractBin, args...)
 	cmd.Stdin = file
 	cmd.Stdout = &ocrOutput
 	cmd.Stderr = &ocrError
@@ -29,3 +34,36 @@ func Parse(file io.Reader) (io.Reader, error) {
 
 	return &ocrOutput, nil
 }
+
+// Cached returns the OCR output for file from cache, running Parse and
+// storing its output on a miss. A nil cache always runs Parse.
+func Cached(cache Cache, file io.Reader) (io.Reader, error) {
+	if cache == nil {
+		return Parse(file)
+	}
+
+	image, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key := Key(image)
+
+	if text, ok := cache.Get(key); ok {
+		return bytes.NewReader(text), nil
+	}
+
+	output, err := Parse(bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := io.ReadAll(output)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Put(key, text)
+
+	return bytes.NewReader(text), nil
+}
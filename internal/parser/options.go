@@ -0,0 +1,105 @@
+package parser
+
+import "git.home/c6bank-transactions/internal/parser/ocr"
+
+// Option customizes how Parse, ParseFile and ParseFiles read a file, and
+// how Export writes transactions.
+type Option func(*options)
+
+type options struct {
+	cache  ocr.Cache
+	filter Filter
+	split  SplitMode
+	costs  bool
+	future FutureMode
+
+	csvColumns bool
+
+	password         string
+	opening, closing *Balance
+	payees           *Payees
+}
+
+// export passes the options on to Export.
+func (o options) export() Option {
+	return func(e *options) {
+		*e = o
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithOCRCache reuses the OCR output of screenshots already seen by cache.
+func WithOCRCache(cache ocr.Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithFilter makes Parse output only the transactions matching filter.
+func WithFilter(filter Filter) Option {
+	return func(o *options) {
+		o.filter = filter
+	}
+}
+
+// WithSplit makes Parse split its output per card.
+func WithSplit(mode SplitMode) Option {
+	return func(o *options) {
+		o.split = mode
+	}
+}
+
+// WithCostSplits makes Parse break purchases into principal and costs, see
+// SplitCosts.
+func WithCostSplits() Option {
+	return func(o *options) {
+		o.costs = true
+	}
+}
+
+// WithFuture sets how projected installments are written, see FutureMode.
+func WithFuture(mode FutureMode) Option {
+	return func(o *options) {
+		o.future = mode
+	}
+}
+
+// WithCSVColumns makes Export add the ID and Category columns to CSV, which
+// by default only has the Date, Payee, Memo and Value ones.
+func WithCSVColumns() Option {
+	return func(o *options) {
+		o.csvColumns = true
+	}
+}
+
+// WithPassword opens password protected PDFs.
+func WithPassword(password string) Option {
+	return func(o *options) {
+		o.password = password
+	}
+}
+
+// WithBalances makes Export write the statement balances: the opening one
+// as the first QIF transaction and the closing one as the OFX LEDGERBAL.
+func WithBalances(opening, closing Balance) Option {
+	return func(o *options) {
+		o.opening, o.closing = &opening, &closing
+	}
+}
+
+// WithPayees cleans up the payees of invoices, statements and screenshots,
+// see Payees.
+func WithPayees(payees *Payees) Option {
+	return func(o *options) {
+		o.payees = payees
+	}
+}
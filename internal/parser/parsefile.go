@@ -1,21 +1,46 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/fasthash/fnv1a"
 )
 
 // ParseFile opens the file at path, detects its format by extension,
-// and returns the parsed transactions.
-func ParseFile(path string) ([]Transaction, error) {
+// and returns the parsed transactions. Previous exports are read back with
+// ReadExport.
+func ParseFile(path string, opts ...Option) ([]Transaction, error) {
+	result := parseFile(path, opts...)
+
+	return result.Transactions, result.Err
+}
+
+// parseFile also returns what else the file shows: invoice totals and
+// statement balances.
+func parseFile(path string, opts ...Option) FileResult {
+	result := FileResult{Path: path}
+
+	if IsExport(path) {
+		result.Source = SourceExport
+		result.Transactions, result.Err = ReadExport(path)
+		if errors.Is(result.Err, ErrInvalidExport) {
+			// not ours, most likely a renamed invoice
+			result.Err = fmt.Errorf("%w: %s", ErrWrongCSVFilename, filepath.Base(path))
+		}
+
+		return result
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("open file %s: %w", path, err)
+		result.Err = fmt.Errorf("open file %s: %w", path, err)
+		return result
 	}
 	defer f.Close()
 
@@ -24,50 +49,173 @@ func ParseFile(path string) ([]Transaction, error) {
 
 	switch ext {
 	case ".csv":
-		if len(name) != 21 || !strings.HasPrefix(name, "Fatura_") {
-			return nil, fmt.Errorf("%w: %s", ErrWrongCSVFilename, name)
+		if len(name) != 21 {
+			result.Err = fmt.Errorf("%w: %s", ErrWrongCSVFilename, name)
+			return result
 		}
 
 		reference, err := time.Parse(time.DateOnly, name[7:17])
 		if err != nil {
-			return nil, fmt.Errorf("invalid date in filename %s: %w", name, err)
+			result.Err = fmt.Errorf("invalid date in filename %s: %w", name, err)
+			return result
+		}
+
+		txs, skipped, err := scanCSVRows(reference, f)
+		if err != nil {
+			result.Err = fmt.Errorf("parse CSV %s: %w", path, err)
+			return result
+		}
+
+		withSource(txs, SourceInvoice)
+		AssignIDs(txs)
+
+		result.Source = SourceInvoice
+		result.Transactions = txs
+		if len(skipped) > 0 {
+			result.Err = &SkippedError{Path: path, Rows: skipped}
+		}
+
+	case ".pdf":
+		info, err := f.Stat()
+		if err != nil {
+			result.Err = fmt.Errorf("stat %s: %w", path, err)
+			return result
 		}
 
-		lines, err := scanCSVRows(reference, f)
+		statement, err := ScanPDF(f, info.Size(), newOptions(opts).password)
 		if err != nil {
-			return nil, fmt.Errorf("parse CSV %s: %w", path, err)
+			result.Err = fmt.Errorf("parse PDF %s: %w", path, err)
+			return result
 		}
 
-		txs, skipped := linesToTypedTransactions(lines)
-		if skipped > 0 {
-			return txs, fmt.Errorf("skipped %d transaction(s) with invalid dates in %s", skipped, path)
+		result.Source = SourceStatement
+		if statement.Kind == PDFInvoice {
+			result.Source = SourceInvoice
+		}
+
+		result.Transactions = statement.Transactions
+		result.Balances = statement.Balances
+		result.Discrepancies = statement.Discrepancies
+		result.Totals = statement.Totals
+
+		if len(statement.Skipped) > 0 {
+			result.Err = &SkippedError{Path: path, Rows: statement.Skipped}
 		}
-		return txs, nil
 
 	case ".jpg", ".jpeg", ".png":
-		transactions, err := ScanImage(f, false)
+		transactions, totals, err := readScreenshot(f, false, opts...)
 		if err != nil {
-			return nil, fmt.Errorf("parse image %s: %w", path, err)
+			result.Err = fmt.Errorf("parse image %s: %w", path, err)
+			return result
 		}
 
-		return transactions, nil
+		withSource(transactions, SourceScreenshot)
+		AssignIDs(transactions)
+
+		result.Source = SourceScreenshot
+		result.Transactions, result.Totals = transactions, totals
 
 	default:
-		return nil, fmt.Errorf("unsupported file format: %s", ext)
+		result.Err = fmt.Errorf("unsupported file format: %s", ext)
+	}
+
+	if payees := newOptions(opts).payees; payees != nil {
+		payees.Apply(result.Transactions)
+	}
+
+	return result
+}
+
+// SkippedRow is a parsed row that could not become a Transaction.
+type SkippedRow struct {
+	Row    int // 1-based position among the file's data rows
+	Line   Line
+	Reason string
+}
+
+// SkippedError is returned by ParseFile alongside the transactions that were
+// parsed when some rows of the file had to be dropped.
+type SkippedError struct {
+	Path string
+	Rows []SkippedRow
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("skipped %d unreadable transaction(s) in %s", len(e.Rows), e.Path)
+}
+
+// FileResult is the outcome of parsing one file with ParseFiles.
+type FileResult struct {
+	Path         string
+	Source       Source // what kind of file it is
+	Transactions []Transaction
+	// Totals are the invoice totals shown in the file. The ones of a
+	// screenshot cover the whole invoice, even when the screenshot shows
+	// only some of its transactions.
+	Totals   []InvoiceTotal
+	Balances []Balance // balances of an account statement
+	// Discrepancies are the statement balances the transactions don't add
+	// up to
+	Discrepancies []BalanceDiscrepancy
+	Err           error
+}
+
+// ParseFiles parses every path with ParseFile using up to workers goroutines.
+// Results are returned in the same order as paths, regardless of which file
+// finishes first, and each file keeps its own error.
+func ParseFiles(paths []string, workers int, opts ...Option) []FileResult {
+	results := make([]FileResult, len(paths))
+
+	inParallel(len(paths), workers, func(i int) {
+		results[i] = parseFile(paths[i], opts...)
+	})
+
+	return results
+}
+
+// inParallel calls do for every index below n using up to workers
+// goroutines, returning when all of them are done.
+func inParallel(n, workers int, do func(i int)) {
+	jobs := make(chan int)
+
+	workers = max(1, min(workers, n))
+
+	var wg sync.WaitGroup
+
+	for range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				do(i)
+			}
+		}()
 	}
+
+	for i := range n {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
 }
 
-// Deduplicate removes duplicate transactions based on Date+Payee+Amount+Memo.
-func Deduplicate(transactions []Transaction) []Transaction {
+// Deduplicate removes duplicate transactions based on Date+Payee+Amount+Memo,
+// along with the ones already present in prior exports.
+func Deduplicate(transactions []Transaction, prior ...[]Transaction) []Transaction {
 	seen := make(map[uint64]struct{}, len(transactions))
 	result := make([]Transaction, 0, len(transactions))
 
+	for _, export := range prior {
+		for _, t := range export {
+			seen[dedupKey(t)] = struct{}{}
+		}
+	}
+
 	for _, t := range transactions {
-		h := fnv1a.Init64
-		h = fnv1a.AddString64(h, t.Date.Format(dateFormat))
-		h = fnv1a.AddString64(h, t.Payee)
-		h = fnv1a.AddString64(h, t.Amount)
-		h = fnv1a.AddString64(h, t.Memo)
+		h := dedupKey(t)
 
 		if _, exists := seen[h]; exists {
 			continue
@@ -80,16 +228,37 @@ func Deduplicate(transactions []Transaction) []Transaction {
 	return result
 }
 
+// dedupKey hashes Date+Payee+Amount+Memo. Amounts are compared in cents when
+// possible, as exports don't always keep the thousands separator.
+func dedupKey(t Transaction) uint64 {
+	amount := t.Amount
+	if cents, err := ParseAmount(amount); err == nil {
+		amount = FormatCents(cents)
+	}
+
+	h := fnv1a.Init64
+	h = fnv1a.AddString64(h, t.Date.Format(dateFormat))
+	h = fnv1a.AddString64(h, t.Payee)
+	h = fnv1a.AddString64(h, amount)
+	h = fnv1a.AddString64(h, t.Memo)
+
+	return h
+}
+
 // linesToTypedTransactions converts []Line (string dates) to []Transaction (typed dates).
-// Returns the number of lines skipped due to invalid dates.
-func linesToTypedTransactions(lines []Line) ([]Transaction, int) {
+// Returns the lines skipped due to invalid dates.
+func linesToTypedTransactions(lines []Line) ([]Transaction, []SkippedRow) {
 	transactions := make([]Transaction, 0, len(lines))
-	var skipped int
+	var skipped []SkippedRow
 
-	for _, l := range lines {
+	for i, l := range lines {
 		date, err := time.Parse(dateFormat, l[0])
 		if err != nil || date.IsZero() {
-			skipped++
+			skipped = append(skipped, SkippedRow{
+				Row:    i + 1,
+				Line:   l,
+				Reason: fmt.Sprintf("invalid date %q", l[0]),
+			})
 			continue
 		}
 
@@ -1,6 +1,11 @@
 package parser_test
 
 import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -72,11 +77,57 @@ func TestParseFile_CSVContent(t *testing.T) {
 
 	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), transactions[0].Date)
 	assert.Equal(t, "MERCADO EXTRA", transactions[0].Payee)
+	assert.Equal(t, "1234", transactions[0].Card)
+	assert.Equal(t, "DANILO", transactions[0].CardName)
+	assert.Equal(t, "Compras", transactions[0].Category)
+	assert.False(t, transactions[0].Installment)
 	// Amazon BR 1/3 generates 3 installments: Jan, Feb, Mar
 	assert.Equal(t, time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), transactions[1].Date)
 	assert.Equal(t, "AMAZON BR", transactions[1].Payee)
 	assert.Equal(t, time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC), transactions[2].Date)
 	assert.Equal(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), transactions[3].Date)
+
+	for i, tx := range transactions[1:] {
+		assert.Equal(t, "5678", tx.Card)
+		assert.True(t, tx.Installment)
+		assert.Equal(t, i > 0, tx.Future, "only the installments after 1/3 are projected")
+	}
+}
+
+func TestParseFile_MalformedInstallments(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "Fatura_2026-01-15.csv")
+	content := "Data;Nome Cartão;Final Cartão;Categoria;Descrição;Parcela;Valor (em US$);Cotação (em R$);Valor (em R$)\n" +
+		"01/01/2026;DANILO;1234;Compras;MERCADO;Única;;;10,00\n" +
+		"32/01/2026;DANILO;1234;Compras;LOJA;1/3;;;30,00\n" +
+		"05/01/2026;DANILO;1234;Compras;AMAZON;x/3;;;20,00\n" +
+		"05/01/2026;DANILO;1234;Compras;CINEMA;4/3;;;5,00\n" +
+		";;;;;;;;\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	// the blank row is not reported
+	transactions, err := parser.ParseFile(path)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "MERCADO", transactions[0].Payee)
+
+	var skipped *parser.SkippedError
+	require.ErrorAs(t, err, &skipped)
+	require.Len(t, skipped.Rows, 3)
+	assert.Equal(t, 2, skipped.Rows[0].Row)
+	assert.Equal(t, `invalid date "32/01/2026"`, skipped.Rows[0].Reason)
+	assert.Equal(t, parser.Line{"05/01/2026", "AMAZON", "x/3", "-20,00"}, skipped.Rows[1].Line)
+	assert.Equal(t, `invalid installment "x/3"`, skipped.Rows[1].Reason)
+	assert.Equal(t, `invalid installment "4/3"`, skipped.Rows[2].Reason)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	upload, err := parser.ParseUpload(filepath.Base(path), f, 0, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, transactions, upload.Transactions)
+	assert.Equal(t, skipped.Rows, upload.Skipped, "uploads report skipped rows to the caller")
 }
 
 func TestDeduplicate(t *testing.T) {
@@ -128,4 +179,110 @@ func TestDeduplicate(t *testing.T) {
 			assert.Len(t, result, tt.wantLen)
 		})
 	}
+
+	t.Run("prior export", func(t *testing.T) {
+		t.Parallel()
+
+		prior := []parser.Transaction{{Date: date, Payee: "A", Memo: "m1", Amount: "1000,00"}}
+		transactions := []parser.Transaction{
+			{Date: date, Payee: "A", Memo: "m1", Amount: "1.000,00"},
+			{Date: date, Payee: "B", Memo: "m1", Amount: "1.000,00"},
+		}
+
+		result := parser.Deduplicate(transactions, prior)
+		require.Len(t, result, 1)
+		assert.Equal(t, "B", result[0].Payee)
+	})
+}
+
+func TestParseFiles(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{
+		"testdata/Fatura_2026-01-15.csv",
+		"testdata/dummy.xlsx",
+		"testdata/Fatura_2026-01-15.csv",
+		"testdata/nonexistent.csv",
+	}
+
+	for _, workers := range []int{0, 1, 4, 10} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			t.Parallel()
+
+			results := parser.ParseFiles(paths, workers)
+			require.Len(t, results, len(paths))
+
+			for i, result := range results {
+				assert.Equal(t, paths[i], result.Path)
+			}
+
+			assert.NoError(t, results[0].Err)
+			assert.Len(t, results[0].Transactions, 4)
+			assert.ErrorContains(t, results[1].Err, "unsupported file format")
+			assert.NoError(t, results[2].Err)
+			assert.Len(t, results[2].Transactions, 4)
+			assert.ErrorContains(t, results[3].Err, "open file")
+		})
+	}
+}
+
+func TestParseUploads(t *testing.T) {
+	t.Parallel()
+
+	invoice, err := os.ReadFile("testdata/Fatura_2026-01-15.csv")
+	require.NoError(t, err)
+
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+
+	for name, content := range map[string][]byte{"Fatura_2026-01-15.csv": invoice, "dummy.xlsx": []byte("xlsx")} {
+		w, err := form.CreateFormFile("file", name)
+		require.NoError(t, err)
+
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, form.Close())
+
+	uploads, err := multipart.NewReader(body, form.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+
+	files := uploads.File["file"]
+	results := parser.ParseUploads(files, 2, "", false)
+	require.Len(t, results, len(files))
+
+	for i, result := range results {
+		assert.Equal(t, files[i].Filename, result.Path)
+
+		if result.Path == "dummy.xlsx" {
+			assert.ErrorContains(t, result.Err, "invalid file")
+		} else {
+			assert.NoError(t, result.Err)
+			assert.Len(t, result.Transactions, 4)
+		}
+	}
+}
+
+func TestLinesToTypedTransactions(t *testing.T) {
+	t.Parallel()
+
+	lines := []parser.Line{
+		{"01/01/2026", "A", "m1", "10,00"},
+		{"", "B", "m2", "20,00"},
+		{"32/01/2026", "C", "m3", "30,00"},
+	}
+
+	transactions, skipped := parser.LinesToTypedTransactions(lines)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "A", transactions[0].Payee)
+
+	require.Len(t, skipped, 2)
+	assert.Equal(t, 2, skipped[0].Row)
+	assert.Equal(t, lines[1], skipped[0].Line)
+	assert.Equal(t, `invalid date ""`, skipped[0].Reason)
+	assert.Equal(t, 3, skipped[1].Row)
+
+	err := &parser.SkippedError{Path: "file.csv", Rows: skipped}
+	assert.EqualError(t, err, "skipped 2 unreadable transaction(s) in file.csv")
 }
@@ -17,74 +17,252 @@ var ErrWrongCSVFilename = errors.New("the filename should be in the form Fatura_
 // Line is: date, payee, memo, value
 type Line [4]string
 
-func Parse(name string, file multipart.File, size int64, password string, includeProcessing bool) (io.Reader, string, error) {
-	var (
-		err   error
-		qtype qif.QIFType
-		lines []Line
-	)
-
+func Parse(name string, file multipart.File, size int64, password string, includeProcessing bool, opts ...Option) (io.Reader, string, Upload, error) {
+	o := newOptions(opts)
 	outputname := strings.TrimSuffix(name, filepath.Ext(name))
 
+	upload, err := ParseUpload(name, file, size, password, includeProcessing, opts...)
+	if err != nil {
+		return nil, "", Upload{}, err
+	}
+
+	if opening, closing, ok := StatementBalances(upload.Balances); ok {
+		o.opening, o.closing = &opening, &closing
+	}
+
+	transactions := upload.Transactions
+
+	if upload.Type == "" {
+		output, outputname, err := imagesOutput(outputname, transactions, o)
+
+		return output, outputname, upload, err
+	}
+
+	if o.costs {
+		if transactions, err = SplitCosts(transactions); err != nil {
+			return nil, "", Upload{}, err
+		}
+	}
+
+	transactions = o.filter.Apply(transactions)
+
+	output, outputname, err := o.output(FormatQIF, upload.Type, outputname, ".qif", transactions)
+
+	return output, outputname, upload, err
+}
+
+// Upload is what an uploaded file holds: its transactions, with their IDs,
+// and the QIF type of the account they belong to. Screenshots have no
+// account type, as they are converted to CSV. Skipped are the rows that
+// could not be read and account statements also have their balances and the
+// ones the transactions don't add up to, for the caller to report.
+type Upload struct {
+	Transactions  []Transaction
+	Type          qif.QIFType
+	Balances      []Balance
+	Discrepancies []BalanceDiscrepancy
+	Skipped       []SkippedRow
+}
+
+// ParseUpload reads an uploaded file, cleaning up payees when WithPayees is
+// given.
+func ParseUpload(name string, file multipart.File, size int64, password string, includeProcessing bool, opts ...Option) (Upload, error) {
+	upload, err := readUpload(name, file, size, password, includeProcessing, opts...)
+	if err != nil {
+		return Upload{}, err
+	}
+
+	if payees := newOptions(opts).payees; payees != nil {
+		payees.Apply(upload.Transactions)
+	}
+
+	return upload, nil
+}
+
+// ParseUploads reads every uploaded file with ParseUpload using up to workers
+// goroutines, as ParseFiles does with paths. Each result is named after its
+// file and has a SkippedError when some rows could not be read.
+func ParseUploads(files []*multipart.FileHeader, workers int, password string, includeProcessing bool, opts ...Option) []FileResult {
+	results := make([]FileResult, len(files))
+
+	inParallel(len(files), workers, func(i int) {
+		results[i] = parseUploadedFile(files[i], password, includeProcessing, opts...)
+	})
+
+	return results
+}
+
+func parseUploadedFile(header *multipart.FileHeader, password string, includeProcessing bool, opts ...Option) FileResult {
+	result := FileResult{Path: header.Filename}
+
+	file, err := header.Open()
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer file.Close()
+
+	upload, err := ParseUpload(header.Filename, file, header.Size, password, includeProcessing, opts...)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.Transactions = upload.Transactions
+	result.Balances = upload.Balances
+	result.Discrepancies = upload.Discrepancies
+
+	if len(upload.Skipped) > 0 {
+		result.Err = &SkippedError{Path: header.Filename, Rows: upload.Skipped}
+	}
+
+	return result
+}
+
+func readUpload(name string, file multipart.File, size int64, password string, includeProcessing bool, opts ...Option) (Upload, error) {
+	var upload Upload
+
 	switch strings.ToLower(filepath.Ext(name)) {
 	case ".pdf":
-		qtype = qif.BankType
-
-		lines, err = scanPDFRows(file, password, size)
+		statement, err := ScanPDF(file, size, password)
 		if err != nil {
-			return nil, "", err
+			return Upload{}, err
+		}
+
+		if statement.Kind == PDFInvoice {
+			return Upload{Transactions: statement.Transactions, Type: qif.CreditCardType, Skipped: statement.Skipped}, nil
 		}
+
+		return Upload{
+			Transactions:  statement.Transactions,
+			Type:          qif.BankType,
+			Balances:      statement.Balances,
+			Discrepancies: statement.Discrepancies,
+			Skipped:       statement.Skipped,
+		}, nil
 	case ".csv":
-		qtype = qif.CreditCardType
+		upload.Type = qif.CreditCardType
 
 		if len(name) != 21 || !strings.HasPrefix(name, "Fatura_") {
-			return nil, "", ErrWrongCSVFilename
+			return Upload{}, ErrWrongCSVFilename
 		}
 
 		reference, err := time.Parse(time.DateOnly, name[7:17])
 		if err != nil {
 			fmt.Printf("ERROR error parsing CSV filename %q: %v", name, err)
 
-			return nil, "", ErrWrongCSVFilename
+			return Upload{}, ErrWrongCSVFilename
 		}
 
-		lines, err = scanCSVRows(reference, file)
+		upload.Transactions, upload.Skipped, err = scanCSVRows(reference, file)
+		if err != nil {
+			return Upload{}, err
+		}
+
+		withSource(upload.Transactions, SourceInvoice)
+	case ".jpg", ".jpeg", ".png":
+		var err error
+
+		upload.Transactions, _, err = readScreenshot(file, includeProcessing, WithOCRCache(newOptions(opts).cache))
 		if err != nil {
-			return nil, "", err
+			return Upload{}, err
 		}
-	case ".jpg", ".png":
-		return parseImages(outputname, file, includeProcessing)
+
+		withSource(upload.Transactions, SourceScreenshot)
 	default:
-		return nil, "", fmt.Errorf("invalid file %s", name)
+		return Upload{}, fmt.Errorf("invalid file %s", name)
 	}
 
-	output, err := qif.Parse(qtype, linesToTransactions(lines))
+	AssignIDs(upload.Transactions)
 
-	return output, outputname + ".qif", err
+	return upload, nil
 }
 
-func linesToTransactions(lines []Line) []qif.Transaction {
-	qt := make([]qif.Transaction, 0, len(lines))
+// imagesOutput converts the transactions of a screenshot to CSV.
+func imagesOutput(name string, transactions []Transaction, o options) (io.Reader, string, error) {
+	selected := o.filter.Apply(transactions)
 
-	for _, l := range lines {
-		qt = append(qt, qif.Transaction{
-			Date:   l[0],
-			Payee:  l[1],
-			Memo:   l[2],
-			Amount: l[3],
-		})
+	if o.split == SplitAccounts {
+		return nil, "", fmt.Errorf("%w: screenshots are converted to CSV, which has no accounts", ErrInvalidSplit)
 	}
 
-	return qt
+	return o.output(FormatCSV, "", name+"-parsed", ".csv", selected)
 }
 
-func parseImages(name string, file io.ReadSeeker, includeProcessing bool) (io.Reader, string, error) {
-	transactions, err := ScanImage(file, includeProcessing)
+// output renders what Parse returns: a single file named base+ext, or a zip
+// named base.zip when splitting files per card or separating the projected
+// installments into a "-scheduled" file.
+func (o options) output(format Format, qtype qif.QIFType, base, ext string, transactions []Transaction) (io.Reader, string, error) {
+	files, err := Render(format, qtype, base+ext, transactions, o.export())
 	if err != nil {
 		return nil, "", err
 	}
 
-	output, err := TransactionsToCSV(transactions)
+	if o.split != SplitFiles && o.future != FutureSeparate {
+		return files[0].Content, base + ext, nil
+	}
+
+	output, err := zipFiles(files)
+
+	return output, base + ".zip", err
+}
+
+// Render renders transactions in format into files named after name: a
+// single one by default, one per card named with SplitName when
+// WithSplit(SplitFiles) is given, and the projected installments apart, in
+// files named with ScheduledName, when WithFuture(FutureSeparate) is given.
+// The other options are passed on to Export, or to ExportAccounts with
+// WithSplit(SplitAccounts).
+func Render(format Format, qtype qif.QIFType, name string, transactions []Transaction, opts ...Option) ([]OutputFile, error) {
+	o := newOptions(opts)
+
+	if o.future != FutureSeparate {
+		return o.render(format, qtype, name, transactions)
+	}
+
+	posted, scheduled := SeparateFuture(transactions)
+
+	files, err := o.render(format, qtype, name, posted)
+	if err != nil {
+		return nil, err
+	}
+
+	more, err := o.render(format, qtype, ScheduledName(name), scheduled)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range more {
+		more[i].Scheduled = true
+	}
+
+	return append(files, more...), nil
+}
+
+// render renders transactions into files named after name: one per card when
+// splitting files, a single one otherwise.
+func (o options) render(format Format, qtype qif.QIFType, name string, transactions []Transaction) ([]OutputFile, error) {
+	var (
+		output io.Reader
+		err    error
+	)
+
+	if o.future == FutureExclude {
+		transactions, _ = SeparateFuture(transactions)
+	}
+
+	switch o.split {
+	case SplitFiles:
+		return filesByCard(format, qtype, name, GroupByCard(transactions), o.export())
+	case SplitAccounts:
+		output, err = ExportAccounts(qtype, GroupByCard(transactions), o.export())
+	default:
+		output, err = Export(format, qtype, transactions, o.export())
+	}
+
+	if err != nil {
+		return nil, err
+	}
 
-	return output, name + "-parsed.csv", err
+	return []OutputFile{{Name: name, Content: output, Transactions: len(transactions)}}, nil
 }
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidAlias = errors.New("invalid payee alias")
+
+var (
+	// regexAcquirer splits "PAG*JOSE" into the acquirer and the merchant
+	regexAcquirer = regexp.MustCompile(`^([A-Za-z0-9.]+)\s*\*\s*(.*)$`)
+	regexSpaces   = regexp.MustCompile(`\s+`)
+
+	// acquirers are payment processors and marketplaces printing their
+	// prefix before the merchant, which is left alone
+	acquirers = map[string]bool{
+		"PAG": true, "PAGSEGURO": true, "PS": true, "MP": true, "MERCADOPAGO": true, "PG": true,
+		"PAYPAL": true, "PP": true, "EBANX": true, "EBN": true, "EC": true, "SUMUP": true,
+		"STONE": true, "PICPAY": true, "ZP": true, "SM": true, "HNA": true, "IZ": true,
+		"IFD": true, "IFOOD": true, "RAPPI": true, "DL": true, "CIELO": true, "GETNET": true,
+	}
+
+	// brands are merchants printing what was bought after them, as in
+	// "UBER *TRIP", which the brand names alone
+	brands = map[string]string{
+		"UBER": "Uber", "UBERTRIP": "Uber", "99": "99", "GOOGLE": "Google",
+		"APPLE.COM": "Apple", "AMAZON": "Amazon", "NETFLIX": "Netflix", "SPOTIFY": "Spotify",
+	}
+
+	// upperWords keep their case when the payee is title-cased
+	upperWords = map[string]bool{
+		"BR": true, "SA": true, "LTDA": true, "ME": true, "EPP": true, "EIRELI": true, "CIA": true,
+		"SP": true, "RJ": true, "MG": true, "PR": true, "RS": true, "SC": true, "DF": true, "BA": true,
+	}
+
+	// lowerWords are the connectives left lowercase when the payee is
+	// title-cased
+	lowerWords = map[string]bool{"DE": true, "DA": true, "DO": true, "DAS": true, "DOS": true, "E": true}
+)
+
+// payeeAlias is a line of the alias file: a canonical name and the
+// normalized variant it replaces, a prefix when it ended in "*".
+type payeeAlias struct {
+	name    string
+	variant string
+	prefix  bool
+}
+
+func (a payeeAlias) match(normalized string) bool {
+	return normalized == a.variant || (a.prefix && strings.HasPrefix(normalized, a.variant))
+}
+
+// Payees cleans up payees: acquirer prefixes ("IFD*", "PAG*", "MP *") are
+// cut, brands followed by what was bought ("UBER *TRIP") become the brand,
+// the payee is title-cased and aliases collapse the variants of a merchant
+// into its canonical name.
+type Payees struct {
+	aliases []payeeAlias
+}
+
+// ReadPayeeAliases reads an alias file: one merchant per line, its
+// canonical name, "=" and the variants separated by commas. Variants are
+// compared as NormalizePayee does and may end in "*" to match every payee
+// starting with them, either before or after the acquirer prefix is cut.
+// Blank lines and lines starting with "#" are skipped.
+//
+//	# canonical name = variants
+//	Restaurante X = RESTAURANTE X LTDA, REST X*
+func ReadPayeeAliases(r io.Reader) (*Payees, error) {
+	payees := new(Payees)
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		name, variants, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w on line %d: %q, use name = variant, variant", ErrInvalidAlias, line, text)
+		}
+
+		for _, variant := range append(strings.Split(variants, ","), name) {
+			variant = strings.TrimSpace(variant)
+			prefix := strings.HasSuffix(variant, "*")
+
+			if v := NormalizePayee(strings.TrimSuffix(variant, "*")); v != "" {
+				payees.aliases = append(payees.aliases, payeeAlias{name: name, variant: v, prefix: prefix})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return payees, nil
+}
+
+// LoadPayeeAliases reads the alias file at path, a missing file meaning no
+// aliases.
+func LoadPayeeAliases(path string) (*Payees, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return new(Payees), nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	payees, err := ReadPayeeAliases(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return payees, nil
+}
+
+// Normalize returns the clean payee.
+func (p *Payees) Normalize(payee string) string {
+	clean := strings.Trim(regexSpaces.ReplaceAllString(payee, " "), " -*.")
+
+	if m := regexAcquirer.FindStringSubmatch(clean); m != nil {
+		prefix := strings.ToUpper(m[1])
+
+		switch brand, ok := brands[prefix]; {
+		case ok:
+			clean = brand
+		case acquirers[prefix] && m[2] != "":
+			clean = m[2]
+		}
+	}
+
+	for _, a := range p.aliases {
+		if a.match(NormalizePayee(clean)) || a.match(NormalizePayee(payee)) {
+			return a.name
+		}
+	}
+
+	return titleCase(clean)
+}
+
+// Apply normalizes the payee of transactions, keeping the raw payee in the
+// memo when it was more than tidied up: before the invoice month, so memos
+// still end with it.
+func (p *Payees) Apply(transactions []Transaction) {
+	for i := range transactions {
+		t := &transactions[i]
+
+		raw := strings.TrimSpace(t.Payee)
+		if raw == "" {
+			continue
+		}
+
+		t.Payee = p.Normalize(raw)
+
+		if NormalizePayee(raw) != NormalizePayee(t.Payee) {
+			t.Memo = withRawPayee(t.Memo, raw)
+		}
+	}
+}
+
+func withRawPayee(memo, raw string) string {
+	if m := regexMemoReference.FindStringIndex(memo); m != nil {
+		return strings.TrimSpace(strings.TrimSpace(memo[:m[0]])+" "+raw) + " " + memo[m[0]:]
+	}
+
+	return strings.TrimSpace(memo + " " + raw)
+}
+
+// titleCase capitalizes the words of payee, but for company suffixes and
+// states ("LTDA", "SP"), connectives ("de") and words with digits.
+func titleCase(payee string) string {
+	words := strings.Fields(payee)
+
+	for i, w := range words {
+		upper := strings.ToUpper(w)
+
+		switch {
+		case upperWords[upper] || strings.ContainsAny(w, "0123456789"):
+			words[i] = upper
+		case lowerWords[upper] && i > 0:
+			words[i] = strings.ToLower(w)
+		default:
+			runes := []rune(strings.ToLower(w))
+			words[i] = strings.ToUpper(string(runes[:1])) + string(runes[1:])
+		}
+	}
+
+	return strings.Join(words, " ")
+}
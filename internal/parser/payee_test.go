@@ -0,0 +1,78 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayees_Normalize(t *testing.T) {
+	t.Parallel()
+
+	payees, err := parser.ReadPayeeAliases(strings.NewReader(`# merchants
+Restaurante X = RESTAURANTE X LTDA, REST X*
+
+Padaria Pão Quente = PANIFICADORA PAO QUENTE
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		payee string
+		want  string
+	}{
+		{"IFD*RESTAURANTE X", "Restaurante X"},
+		{"IFD*REST X SAO PAULO", "Restaurante X"},
+		{"RESTAURANTE X LTDA", "Restaurante X"},
+		{"PAG*JOSEDASILVA", "Josedasilva"},
+		{"MP *LOJA DO JOAO", "Loja do Joao"},
+		{"UBER *TRIP", "Uber"},
+		{"UBER* TRIP HELP.UBER.COM", "Uber"},
+		{"PANIFICADORA PÃO QUENTE", "Padaria Pão Quente"},
+		{"AMAZON BR", "Amazon BR"},
+		{"POSTO 24H LTDA", "Posto 24H LTDA"},
+		{"  NETFLIX.COM  ", "Netflix.com"},
+		{"XYZ*ABC", "Xyz*abc"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, payees.Normalize(tt.payee), tt.payee)
+	}
+}
+
+func TestPayees_Apply(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Payee: "IFD*RESTAURANTE X", Memo: "2/10 1234 FULANO 03/2026"},
+		{Payee: "MERCADO", Memo: "Única 1234 FULANO 03/2026"},
+		{Payee: "PIX ENVIADO", Memo: "CICLANO"},
+	}
+
+	new(parser.Payees).Apply(transactions)
+
+	assert.Equal(t, "Restaurante X", transactions[0].Payee)
+	assert.Equal(t, "2/10 1234 FULANO IFD*RESTAURANTE X 03/2026", transactions[0].Memo)
+	assert.Equal(t, "Mercado", transactions[1].Payee)
+	assert.Equal(t, "Única 1234 FULANO 03/2026", transactions[1].Memo)
+	assert.Equal(t, "Pix Enviado", transactions[2].Payee)
+	assert.Equal(t, "CICLANO", transactions[2].Memo)
+}
+
+func TestReadPayeeAliases_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := parser.ReadPayeeAliases(strings.NewReader("Restaurante X = REST X\nREST Y\n"))
+	require.ErrorIs(t, err, parser.ErrInvalidAlias)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestLoadPayeeAliases_Missing(t *testing.T) {
+	t.Parallel()
+
+	payees, err := parser.LoadPayeeAliases(t.TempDir() + "/aliases.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "Amazon BR", payees.Normalize("AMAZON BR"))
+}
@@ -1,53 +1,283 @@
 package parser
 
 import (
-	"bufio"
-	"bytes"
+	"cmp"
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/ledongthuc/pdf"
 )
 
-const lf = "\n"
+// statement table columns
+const (
+	colDate = iota
+	colDescription
+	colDocument
+	colValue
+	colType
+	numColumns
+)
 
-// 0    1    2     3    4     5
-// line date payee memo value type
-var transactionRegexp = regexp.MustCompile(`(?P<date>[0-9/]{10})\s+(?P<payee>[A-Z0-9., ]+)\s*-?\s*(?P<memo>.*)\s+[0-9]{12}\s+(?P<value>[0-9.]+,[0-9]{2})\s+(?P<type>[CD])`)
+// columnHeaders maps the normalized headers of the statement table to their
+// column.
+var columnHeaders = map[string]int{
+	"DATA":        colDate,
+	"DESCRICAO":   colDescription,
+	"HISTORICO":   colDescription,
+	"DOCUMENTO":   colDocument,
+	"N DOCUMENTO": colDocument,
+	"DOC":         colDocument,
+	"VALOR":       colValue,
+	"VALOR R":     colValue,
+	"C D":         colType,
+	"D C":         colType,
+	"TIPO":        colType,
+}
 
-func scanPDFRows(file io.ReaderAt, pass string, size int64) ([]Line, error) {
-	content, err := readPDF(file, size, pass)
-	if err != nil {
-		return nil, err
+var (
+	regexPDFDate     = regexp.MustCompile(`^\d{2}/\d{2}/\d{4}$`)
+	regexPDFAmount   = regexp.MustCompile(`^(?:R\$\s*)?-?\d{1,3}(?:\.?\d{3})*,\d{2}$`)
+	regexPDFDocument = regexp.MustCompile(`^\d{6,}$`)
+)
+
+// pdfWord is a run of text of a PDF row, X and W telling where it starts and
+// how wide it is, in points.
+type pdfWord struct {
+	X, W float64
+	S    string
+}
+
+// pdfRow is a line of text of a PDF page.
+type pdfRow struct {
+	Page  int
+	Words []pdfWord
+}
+
+func (r pdfRow) text() string {
+	words := make([]string, len(r.Words))
+	for i, w := range r.Words {
+		words[i] = w.S
 	}
 
-	var lines []Line
-	scanner := bufio.NewScanner(content)
+	return strings.Join(words, " ")
+}
+
+// pdfColumn is a column of the statement table and the span of its header.
+type pdfColumn struct {
+	kind       int
+	start, end float64
+}
+
+// pdfCells are the texts of a row in each column of the statement table.
+type pdfCells [numColumns]string
+
+// scanStatementRows reads the transaction rows and the balances of the
+// statement. Words are assigned to the columns of the last table header seen,
+// so payees may have any character; before the first header, columns are
+// told apart by their content. Rows that look like transactions (a date in
+// the date column, or an amount with its C/D) but don't parse are returned
+// as skipped, with the reason.
+func scanStatementRows(rows []pdfRow) ([]Line, []Balance, []SkippedRow) {
+	var (
+		lines    []Line
+		balances []Balance
+		skipped  []SkippedRow
+		columns  []pdfColumn
+	)
+
+	for _, row := range rows {
+		if header, ok := headerColumns(row.Words); ok {
+			columns = header
+			continue
+		}
 
-	for scanner.Scan() {
-		record := transactionRegexp.FindStringSubmatch(scanner.Text())
-		if len(record) != 6 {
+		if balance, ok := parseBalance(row.text()); ok {
+			balance.Row = len(lines)
+			balances = append(balances, balance)
+
+			continue
+		}
+
+		cells := splitCells(row.Words, columns)
+		if !cells.transaction() {
 			continue
 		}
 
-		if record[5] == "D" {
-			record[4] = fmt.Sprintf("-%s", record[4])
+		line, reason := cells.line()
+		if reason != "" {
+			skipped = append(skipped, SkippedRow{
+				Row:    len(lines) + len(skipped) + 1,
+				Line:   line,
+				Reason: fmt.Sprintf("page %d: %s", row.Page, reason),
+			})
+
+			continue
 		}
 
-		lines = append(lines, Line{record[1], record[2], record[3], record[4]})
+		lines = append(lines, line)
+	}
+
+	return lines, balances, skipped
+}
+
+// headerColumns finds the columns of the statement table when words are its
+// header: at least the date, the value and one more column.
+func headerColumns(words []pdfWord) ([]pdfColumn, bool) {
+	var (
+		columns []pdfColumn
+		found   [numColumns]bool
+	)
+
+	for _, w := range words {
+		kind, ok := columnHeaders[NormalizePayee(w.S)]
+		if !ok || found[kind] {
+			continue
+		}
+
+		found[kind] = true
+		columns = append(columns, pdfColumn{kind: kind, start: w.X, end: w.X + w.W})
+	}
+
+	if !found[colDate] || !found[colValue] || len(columns) < 3 {
+		return nil, false
+	}
+
+	slices.SortFunc(columns, func(a, b pdfColumn) int {
+		return cmp.Compare(a.start, b.start)
+	})
+
+	return columns, true
+}
+
+// splitCells assigns every word to the column whose header is closest: the
+// boundary between two columns lies halfway between their headers, so
+// right-aligned values still land under theirs. Without columns, the cells
+// are told apart by their content.
+func splitCells(words []pdfWord, columns []pdfColumn) pdfCells {
+	if len(columns) == 0 {
+		return contentCells(words)
+	}
+
+	var parts [numColumns][]string
+
+	for _, w := range words {
+		center := w.X + w.W/2
+		kind := columns[len(columns)-1].kind
+
+		for i := 0; i < len(columns)-1; i++ {
+			if center < (columns[i].end+columns[i+1].start)/2 {
+				kind = columns[i].kind
+				break
+			}
+		}
+
+		parts[kind] = append(parts[kind], w.S)
+	}
+
+	var cells pdfCells
+	for i, p := range parts {
+		cells[i] = strings.Join(p, " ")
+	}
+
+	return cells
+}
+
+// contentCells reads a row as date, description, document, value and C/D,
+// all but the date and the description being optional.
+func contentCells(words []pdfWord) pdfCells {
+	var cells pdfCells
+
+	if len(words) == 0 || !regexPDFDate.MatchString(words[0].S) {
+		return cells
+	}
+
+	cells[colDate], words = words[0].S, words[1:]
+
+	if n := len(words); n > 0 && (words[n-1].S == "C" || words[n-1].S == "D") {
+		cells[colType], words = words[n-1].S, words[:n-1]
+	}
+
+	if n := len(words); n > 0 && regexPDFAmount.MatchString(words[n-1].S) {
+		cells[colValue], words = words[n-1].S, words[:n-1]
+	}
+
+	if n := len(words); n > 0 && regexPDFDocument.MatchString(words[n-1].S) {
+		cells[colDocument], words = words[n-1].S, words[:n-1]
 	}
 
-	return lines, nil
+	cells[colDescription] = pdfRow{Words: words}.text()
+
+	return cells
+}
+
+// transaction tells whether the row looks like a transaction.
+func (c pdfCells) transaction() bool {
+	return regexPDFDate.MatchString(c[colDate]) ||
+		(regexPDFAmount.MatchString(c[colValue]) && (c[colType] == "C" || c[colType] == "D"))
 }
 
-func readPDF(file io.ReaderAt, size int64, pass string) (io.Reader, error) {
+// line parses the cells, returning why when they aren't a transaction. The
+// description is split into payee and memo at " - ", as in
+// "PIX RECEBIDO - FULANO".
+func (c pdfCells) line() (Line, string) {
+	payee, memo, _ := strings.Cut(c[colDescription], " - ")
+	value, kind := c[colValue], c[colType]
+
+	// the value may take the C/D when the columns are close together
+	if i := strings.LastIndex(value, " "); i >= 0 && kind == "" && (value[i+1:] == "C" || value[i+1:] == "D") {
+		value, kind = value[:i], value[i+1:]
+	}
+
+	line := Line{c[colDate], strings.TrimSpace(payee), strings.TrimSpace(memo), strings.TrimSpace(value + " " + kind)}
+
+	switch {
+	case c[colDate] == "":
+		return line, "no date"
+	case !isDate(c[colDate]):
+		return line, fmt.Sprintf("invalid date %q", c[colDate])
+	case line[1] == "":
+		return line, "no description"
+	case value == "":
+		return line, "no amount"
+	case !regexPDFAmount.MatchString(value):
+		return line, fmt.Sprintf("invalid amount %q", value)
+	case kind != "" && kind != "C" && kind != "D":
+		return line, fmt.Sprintf("unknown type %q, expected C or D", kind)
+	}
+
+	cents, err := ParseAmount(value)
+	if err != nil {
+		return line, err.Error()
+	}
+
+	if kind == "D" && cents > 0 {
+		cents = -cents
+	}
+
+	line[3] = formatBRL(cents)
+
+	return line, ""
+}
+
+func isDate(s string) bool {
+	_, err := time.Parse(dateFormat, s)
+
+	return err == nil
+}
+
+// readPDF reads the rows of every page, merging the glyphs of each word.
+func readPDF(file io.ReaderAt, size int64, pass string) ([]pdfRow, error) {
 	reader, err := pdf.NewReaderEncrypted(file, size, func() string { return pass })
 	if err != nil {
 		return nil, err
 	}
 
-	buff := new(bytes.Buffer)
+	var result []pdfRow
+
 	totalPage := reader.NumPage()
 
 	for pageIndex := 1; pageIndex <= totalPage; pageIndex++ {
@@ -62,14 +292,43 @@ func readPDF(file io.ReaderAt, size int64, pass string) (io.Reader, error) {
 		}
 
 		for _, row := range rows {
-			for _, word := range row.Content {
-				buff.WriteString(word.S)
-				buff.WriteString(" ")
+			if words := pdfWords(row.Content); len(words) > 0 {
+				result = append(result, pdfRow{Page: pageIndex, Words: words})
 			}
+		}
+	}
 
-			buff.WriteString(lf)
+	return result, nil
+}
+
+// pdfWords merges texts closer than a fifth of their font size, as PDFs
+// often place every glyph on its own, and drops the blank ones.
+func pdfWords(texts pdf.TextHorizontal) []pdfWord {
+	var words []pdfWord
+
+	texts = slices.Clone(texts)
+	slices.SortStableFunc(texts, func(a, b pdf.Text) int {
+		return cmp.Compare(a.X, b.X)
+	})
+
+	merge := false
+
+	for _, t := range texts {
+		if strings.TrimSpace(t.S) == "" {
+			merge = false
+			continue
 		}
+
+		if n := len(words); n > 0 && merge && t.X-(words[n-1].X+words[n-1].W) < t.FontSize/5 {
+			words[n-1].S += t.S
+			words[n-1].W = t.X + t.W - words[n-1].X
+
+			continue
+		}
+
+		words = append(words, pdfWord{X: t.X, W: t.W, S: strings.TrimSpace(t.S)})
+		merge = true
 	}
 
-	return buff, nil
+	return words
 }
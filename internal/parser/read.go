@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"git.home/c6bank-transactions/internal/ofx"
+	"git.home/c6bank-transactions/internal/qif"
+)
+
+// regexCardAccount matches the account names written by ExportAccounts.
+var regexCardAccount = regexp.MustCompile(`^C6 (\d{4})(?: (.+))?$`)
+
+// IsExport tells a file written by this tool (QIF, OFX or our CSV) apart
+// from a C6 statement, invoice or screenshot.
+func IsExport(path string) bool {
+	name := filepath.Base(path)
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".qif", ".ofx":
+		return true
+	case ".csv":
+		return !strings.HasPrefix(name, "Fatura_")
+	}
+
+	return false
+}
+
+// ReadExport reads back the transactions of a previous CSV, QIF or OFX export.
+func ReadExport(path string) ([]Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var transactions []Transaction
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		transactions, err = ReadCSV(f)
+	case ".qif":
+		transactions, err = ReadQIF(f)
+	case ".ofx":
+		transactions, err = ReadOFX(f)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read export %s: %w", path, err)
+	}
+
+	withSource(transactions, SourceExport)
+	AssignIDs(transactions) // exports written before IDs
+
+	return transactions, nil
+}
+
+// ReadQIF reads back the transactions of a QIF written by Export or
+// ExportAccounts, taking the card of each transaction from its account name.
+// Opening balances are left out.
+func ReadQIF(file io.Reader) ([]Transaction, error) {
+	accounts, err := qif.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var transactions []Transaction
+
+	for _, account := range accounts {
+		var card, cardName string
+		if match := regexCardAccount.FindStringSubmatch(account.Name); match != nil {
+			card, cardName = match[1], match[2]
+		}
+
+		for _, qt := range account.Transactions {
+			if qt.Payee == openingBalancePayee && strings.HasPrefix(qt.Category, "[") {
+				continue // a balance, see WithBalances
+			}
+
+			date, err := time.Parse(dateFormat, qt.Date)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", qif.ErrInvalidQIF, err)
+			}
+
+			transactions = append(transactions, Transaction{
+				ID:       qt.ID,
+				Date:     date,
+				Payee:    qt.Payee,
+				Memo:     qt.Memo,
+				Amount:   qt.Amount,
+				Card:     card,
+				CardName: cardName,
+				Category: qt.Category,
+				Splits:   splitsFromQIF(qt.Splits),
+			})
+		}
+	}
+
+	return transactions, nil
+}
+
+// ReadOFX reads back the transactions of an OFX statement. Amounts are
+// converted to the format C6 uses, so they match the other sources.
+func ReadOFX(file io.Reader) ([]Transaction, error) {
+	txs, err := ofx.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]Transaction, 0, len(txs))
+
+	for _, ot := range txs {
+		date, err := time.Parse(ofx.DateFormat, ot.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ofx.ErrInvalidOFX, err)
+		}
+
+		cents, err := ParseAmount(ot.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ofx.ErrInvalidOFX, err)
+		}
+
+		memo, future := unmarkMemo(ot.Memo)
+
+		transactions = append(transactions, Transaction{
+			ID:     ot.ID,
+			Date:   date,
+			Payee:  ot.Name,
+			Memo:   memo,
+			Amount: formatBRL(cents),
+			Future: future,
+		})
+	}
+
+	return transactions, nil
+}
+
+func splitsFromQIF(qs []qif.Split) []Split {
+	if len(qs) == 0 {
+		return nil
+	}
+
+	splits := make([]Split, 0, len(qs))
+	for _, s := range qs {
+		splits = append(splits, Split{Category: s.Category, Memo: s.Memo, Amount: s.Amount})
+	}
+
+	return splits
+}
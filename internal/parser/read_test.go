@@ -0,0 +1,91 @@
+package parser_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadExport(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "MERCADO EXTRA", Memo: "1234 03/2026", Amount: "-1.167,91", Card: "1234", CardName: "DANILO", Category: "Supermercado"},
+		{Date: date.AddDate(0, 0, 1), Payee: "ESTORNO", Amount: "10,00", Card: "5678"},
+	}
+
+	write := func(t *testing.T, name string, format parser.Format) string {
+		t.Helper()
+
+		r, err := parser.Export(format, qif.CreditCardType, transactions, parser.WithCSVColumns())
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(path)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.ReadFrom(r)
+		require.NoError(t, err)
+
+		return path
+	}
+
+	ids := slices.Clone(transactions)
+	parser.AssignIDs(ids)
+
+	for _, format := range []parser.Format{parser.FormatCSV, parser.FormatQIF, parser.FormatOFX} {
+		t.Run(string(format), func(t *testing.T) {
+			t.Parallel()
+
+			path := write(t, "export."+string(format), format)
+			require.True(t, parser.IsExport(path))
+
+			got, err := parser.ParseFile(path)
+			require.NoError(t, err)
+			require.Len(t, got, 2)
+
+			for i, tx := range got {
+				assert.Equal(t, transactions[i].Date, tx.Date)
+				assert.Equal(t, transactions[i].Payee, tx.Payee)
+				assert.Equal(t, transactions[i].Memo, tx.Memo)
+				assert.Equal(t, transactions[i].Amount, tx.Amount)
+				assert.Equal(t, ids[i].ID, tx.ID, "IDs survive the round trip")
+			}
+
+			assert.Empty(t, parser.Deduplicate(transactions, got), "everything is in the prior export")
+		})
+	}
+
+	t.Run("QIF accounts keep the card", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := parser.ExportAccounts(qif.CreditCardType, parser.GroupByCard(transactions))
+		require.NoError(t, err)
+
+		got, err := parser.ReadQIF(r)
+		require.NoError(t, err)
+		require.Len(t, got, 2)
+
+		assert.Equal(t, "1234", got[0].Card)
+		assert.Equal(t, "DANILO", got[0].CardName)
+		assert.Equal(t, "Supermercado", got[0].Category)
+		assert.Equal(t, "5678", got[1].Card)
+	})
+
+	t.Run("invoice is not an export", func(t *testing.T) {
+		t.Parallel()
+
+		assert.False(t, parser.IsExport("testdata/Fatura_2026-01-15.csv"))
+		assert.False(t, parser.IsExport("testdata/IMG_0420.PNG"))
+	})
+}
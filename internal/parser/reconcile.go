@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Realized is a projected installment billed by a later invoice.
+type Realized struct {
+	Projected  Transaction `json:"projected"`
+	Billed     Transaction `json:"billed"`
+	Difference int64       `json:"difference"` // cents, billed minus projected
+}
+
+// Reconciliation is what Reconcile found.
+type Reconciliation struct {
+	Realized []Realized `json:"realized"`
+	// Unbilled are the projections an invoice read for their month doesn't
+	// bill, as the purchase was cancelled or paid off early, followed by the
+	// later projections of the same purchases.
+	Unbilled []Transaction `json:"unbilled"`
+}
+
+// Reconcile matches the projected installments (Future) against the ones
+// billed by later invoices: same card, installment ("2/10") and invoice
+// month, with payees matching as in Matcher.Match. Amounts may differ, as
+// the last installment absorbs the rounding.
+//
+// Realized projections are dropped in favor of the billed installment, which
+// takes the projection's ID so finance apps that imported the projection
+// update it. Projections for a month whose invoice was read but doesn't bill
+// them are dropped as unbilled; the ones for months without an invoice are
+// kept. Consecutive invoices project the same installments again, maybe
+// with a different amount; only the first projection counts.
+func Reconcile(transactions []Transaction) ([]Transaction, Reconciliation) {
+	var (
+		rec       Reconciliation
+		invoiced  = make(map[time.Time]bool)
+		projected = make(map[string]bool)
+		ended     = make(map[string]int) // purchase to its first unbilled installment
+		drop      = make([]bool, len(transactions))
+		billed    = make([]bool, len(transactions))
+	)
+
+	transactions = slices.Clone(transactions)
+
+	for _, t := range transactions {
+		if !t.Future && t.Source == SourceInvoice {
+			invoiced[invoiceMonth(t)] = true
+		}
+	}
+
+	for i, p := range transactions {
+		current, _, ok := p.InstallmentOf()
+		if !p.Future || !ok {
+			continue
+		}
+
+		key := fmt.Sprintf("%s|%d", purchaseKey(p), current)
+		if projected[key] {
+			drop[i] = true
+			continue
+		}
+
+		projected[key] = true
+
+		j, diff := billedInstallment(transactions, billed, p)
+
+		switch {
+		case j >= 0:
+			billed[j] = true
+			drop[i] = true
+			transactions[j].ID = p.ID
+			rec.Realized = append(rec.Realized, Realized{Projected: p, Billed: transactions[j], Difference: diff})
+		case invoiced[invoiceMonth(p)]:
+			drop[i] = true
+			rec.Unbilled = append(rec.Unbilled, p)
+
+			if first, ok := ended[purchaseKey(p)]; !ok || current < first {
+				ended[purchaseKey(p)] = current
+			}
+		}
+	}
+
+	for i, p := range transactions {
+		current, _, _ := p.InstallmentOf()
+
+		if first, ok := ended[purchaseKey(p)]; ok && p.Future && !drop[i] && current > first {
+			drop[i] = true
+			rec.Unbilled = append(rec.Unbilled, p)
+		}
+	}
+
+	kept := make([]Transaction, 0, len(transactions))
+	for i, t := range transactions {
+		if !drop[i] {
+			kept = append(kept, t)
+		}
+	}
+
+	return kept, rec
+}
+
+// billedInstallment finds the billed installment of the projection p not
+// taken yet, the one with the closest amount when there are many, returning
+// -1 when there's none.
+func billedInstallment(transactions []Transaction, taken []bool, p Transaction) (int, int64) {
+	projected, err := ParseAmount(p.Amount)
+	if err != nil {
+		return -1, 0
+	}
+
+	best, diff := -1, int64(0)
+
+	for j, b := range transactions {
+		if b.Future || taken[j] || !sameInstallment(p, b) {
+			continue
+		}
+
+		cents, err := ParseAmount(b.Amount)
+		if err != nil {
+			continue
+		}
+
+		if d := cents - projected; best == -1 || abs(d) < abs(diff) {
+			best, diff = j, d
+		}
+	}
+
+	return best, diff
+}
+
+func sameInstallment(p, b Transaction) bool {
+	if p.Card != "" && b.Card != "" && p.Card != b.Card {
+		return false
+	}
+
+	pc, pt, _ := p.InstallmentOf()
+	bc, bt, ok := b.InstallmentOf()
+	if !ok || pc != bc || pt != bt || !invoiceMonth(p).Equal(invoiceMonth(b)) {
+		return false
+	}
+
+	_, ok = matchPayee(p.Payee, b.Payee)
+
+	return ok
+}
+
+// invoiceMonth is the invoice month of the memo, or the month of the date
+// when the memo has none.
+func invoiceMonth(t Transaction) time.Time {
+	if reference, ok := t.Reference(); ok {
+		return reference
+	}
+
+	return time.Date(t.Date.Year(), t.Date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// purchaseKey identifies the installment purchase of t by card, payee,
+// number of installments and purchase date.
+func purchaseKey(t Transaction) string {
+	current, total, _ := t.InstallmentOf()
+	purchased := t.Date.AddDate(0, 1-max(current, 1), 0)
+
+	return fmt.Sprintf("%s|%s|%d|%s", t.Card, NormalizePayee(t.Payee), total, purchased.Format(time.DateOnly))
+}
+
+func abs(cents int64) int64 {
+	if cents < 0 {
+		return -cents
+	}
+
+	return cents
+}
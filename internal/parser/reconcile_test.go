@@ -0,0 +1,75 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	installment := func(payee, memo, amount string, months int, future bool) parser.Transaction {
+		return parser.Transaction{
+			ID: memo + " " + payee, Date: date.AddDate(0, months, 0), Payee: payee, Memo: memo, Amount: amount,
+			Card: "1234", Installment: true, Future: future, Source: parser.SourceInvoice,
+		}
+	}
+
+	transactions := []parser.Transaction{
+		// January invoice
+		installment("LOJA", "1/3 1234 01/2026", "-33,33", 0, false),
+		installment("LOJA", "2/3 1234 02/2026", "-33,33", 1, true),
+		installment("LOJA", "3/3 1234 03/2026", "-33,33", 2, true),
+		installment("CURSO", "1/3 1234 01/2026", "-50,00", 0, false),
+		installment("CURSO", "2/3 1234 02/2026", "-50,00", 1, true),
+		installment("CURSO", "3/3 1234 03/2026", "-50,00", 2, true),
+		// February invoice: the course was cancelled and the store rounds up
+		// the last installment, projected again
+		installment("LOJA", "2/3 1234 02/2026", "-33,33", 1, false),
+		installment("LOJA", "3/3 1234 03/2026", "-33,34", 2, true),
+		// March invoice
+		installment("LOJA", "3/3 1234 03/2026", "-33,34", 2, false),
+	}
+
+	got, rec := parser.Reconcile(transactions)
+
+	require.Len(t, rec.Realized, 2)
+	assert.Equal(t, "2/3 1234 02/2026", rec.Realized[0].Billed.Memo)
+	assert.Zero(t, rec.Realized[0].Difference)
+	assert.Equal(t, "3/3 1234 03/2026", rec.Realized[1].Billed.Memo)
+	assert.Equal(t, int64(-1), rec.Realized[1].Difference, "the last installment absorbs the rounding")
+	assert.Equal(t, rec.Realized[1].Projected.ID, rec.Realized[1].Billed.ID, "the billed installment keeps the projection's ID")
+
+	require.Len(t, rec.Unbilled, 2)
+	assert.Equal(t, "2/3 1234 02/2026", rec.Unbilled[0].Memo)
+	assert.Equal(t, "3/3 1234 03/2026", rec.Unbilled[1].Memo, "the later installments of a cancelled purchase are dropped")
+	assert.Equal(t, "CURSO", rec.Unbilled[1].Payee)
+
+	require.Len(t, got, 4)
+
+	for _, tx := range got {
+		assert.False(t, tx.Future, "%s %s", tx.Payee, tx.Memo)
+	}
+}
+
+func TestReconcile_KeepsProjectionsWithoutInvoice(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "LOJA", Memo: "1/2 1234 01/2026", Amount: "-10,00", Card: "1234", Installment: true, Source: parser.SourceInvoice},
+		{Date: date.AddDate(0, 1, 0), Payee: "LOJA", Memo: "2/2 1234 02/2026", Amount: "-10,00", Card: "1234", Installment: true, Future: true, Source: parser.SourceInvoice},
+	}
+
+	got, rec := parser.Reconcile(transactions)
+
+	assert.Equal(t, transactions, got)
+	assert.Empty(t, rec.Realized)
+	assert.Empty(t, rec.Unbilled)
+}
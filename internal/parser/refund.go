@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// refundDays is how long after a purchase it may be refunded.
+const refundDays = 90
+
+// regexRefundPrefix finds the words the card prepends to refunds, as in
+// "ESTORNO DE COMPRA LOJA".
+var regexRefundPrefix = regexp.MustCompile(`^(?:(?:ESTORNO|REEMBOLSO|DEVOLUCAO|CHARGEBACK|CANCELAMENTO|CREDITO)(?: (?:DE|DA|DO))?(?: COMPRA)? ?)+`)
+
+// Refund is a card credit matched to the purchase it gives back, in full or
+// in part.
+type Refund struct {
+	Purchase Transaction `json:"purchase"`
+	Refund   Transaction `json:"refund"`
+	Partial  bool        `json:"partial"`
+}
+
+// MatchRefunds matches the card credits ("ESTORNO LOJA" or the purchase's
+// own payee) with the purchase they refund: a debit up to refundDays before,
+// on the same card, with a payee matching as in Matcher.Match once the
+// refund words are cut, and an amount at least the refund's. Exact amounts
+// win over partial refunds, then the closest date. A refund without a payee
+// left ("ESTORNO") matches only exact amounts. Both sides get linked by ID:
+// RefundOf on the refund and RefundedBy on the purchase.
+func MatchRefunds(transactions []Transaction) ([]Transaction, []Refund) {
+	var (
+		refunds  []Refund
+		refunded = make([]bool, len(transactions))
+	)
+
+	transactions = slices.Clone(transactions)
+
+	for i, r := range transactions {
+		cents, err := ParseAmount(r.Amount)
+		if err != nil || cents <= 0 || !refundable(r) || regexCardPayment.MatchString(NormalizePayee(r.Payee)) {
+			continue
+		}
+
+		payee := strings.TrimSpace(regexRefundPrefix.ReplaceAllString(NormalizePayee(r.Payee), ""))
+
+		best, bestExact, bestDays := -1, false, 0
+
+		for j, p := range transactions {
+			if refunded[j] || !refundable(p) || (r.Card != "" && p.Card != "" && r.Card != p.Card) {
+				continue
+			}
+
+			purchase, err := ParseAmount(p.Amount)
+			if err != nil || -purchase < cents {
+				continue
+			}
+
+			days := int(dateOnly(r.Date).Sub(dateOnly(p.Date)) / (24 * time.Hour))
+			if days < 0 || days > refundDays {
+				continue
+			}
+
+			exact := -purchase == cents
+			if payee == "" && !exact {
+				continue
+			}
+
+			if _, ok := matchPayee(payee, p.Payee); payee != "" && !ok {
+				continue
+			}
+
+			if best == -1 || (exact && !bestExact) || (exact == bestExact && days < bestDays) {
+				best, bestExact, bestDays = j, exact, days
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		refunded[best] = true
+		transactions[i].RefundOf = transactions[best].ID
+		transactions[best].RefundedBy = r.ID
+
+		refunds = append(refunds, Refund{Purchase: transactions[best], Refund: transactions[i], Partial: !bestExact})
+	}
+
+	return transactions, refunds
+}
+
+// refundable tells the posted card transactions apart, the only ones
+// refunds are looked for in.
+func refundable(t Transaction) bool {
+	return t.Source != SourceStatement && !t.Future && !t.Processing && t.Transfer == ""
+}
+
+// NetRefunds takes the matched refunds (see MatchRefunds) off their
+// purchase: fully refunded purchases are dropped along with the refund,
+// partially refunded ones keep what was left to pay.
+func NetRefunds(transactions []Transaction) ([]Transaction, error) {
+	credits := make(map[string]int64)
+	ids := make(map[string]bool, len(transactions))
+
+	for _, t := range transactions {
+		ids[t.ID] = true
+	}
+
+	for _, t := range transactions {
+		if t.RefundOf == "" || !ids[t.RefundOf] {
+			continue
+		}
+
+		cents, err := ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		credits[t.RefundOf] += cents
+	}
+
+	result := make([]Transaction, 0, len(transactions))
+
+	for _, t := range transactions {
+		credit, ok := credits[t.ID]
+
+		switch {
+		case t.RefundOf != "" && ids[t.RefundOf]:
+			continue
+		case ok:
+			cents, err := ParseAmount(t.Amount)
+			if err != nil {
+				return nil, err
+			}
+
+			if cents+credit == 0 {
+				continue
+			}
+
+			t.Amount = formatBRL(cents + credit)
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
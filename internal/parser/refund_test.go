@@ -0,0 +1,62 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchRefunds(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	tx := func(id string, d int, payee, amount, card string) parser.Transaction {
+		return parser.Transaction{ID: id, Date: day(d), Payee: payee, Amount: amount, Card: card, Source: parser.SourceInvoice}
+	}
+
+	transactions := []parser.Transaction{
+		tx("shoes", 2, "LOJA DE SAPATOS", "-300,00", "1234"),
+		tx("shoes-other-card", 3, "LOJA DE SAPATOS", "-300,00", "5678"),
+		tx("shoes-refund", 10, "ESTORNO DE COMPRA Loja de Sapatos", "300,00", "1234"),
+		tx("market", 4, "MERCADO", "-150,00", "1234"),
+		tx("market-refund", 6, "MERCADO", "50,00", "1234"),
+		tx("fee", 5, "TARIFA", "-12,00", "1234"),
+		tx("fee-refund", 7, "ESTORNO", "12,00", "1234"),
+		tx("early", 20, "POSTO", "-80,00", "1234"),
+		tx("early-refund", 15, "ESTORNO POSTO", "80,00", "1234"),
+		tx("payment", 8, "Inclusao de Pagamento", "150,00", "1234"),
+	}
+
+	linked, refunds := parser.MatchRefunds(transactions)
+	require.Len(t, refunds, 3)
+
+	assert.Equal(t, "shoes", refunds[0].Purchase.ID)
+	assert.Equal(t, "shoes-refund", refunds[0].Refund.ID)
+	assert.False(t, refunds[0].Partial)
+
+	assert.Equal(t, "market", refunds[1].Purchase.ID)
+	assert.True(t, refunds[1].Partial)
+
+	assert.Equal(t, "fee", refunds[2].Purchase.ID, "a refund without payee matches the exact amount")
+
+	assert.Equal(t, "shoes-refund", linked[0].RefundedBy)
+	assert.Equal(t, "shoes", linked[2].RefundOf)
+	assert.Empty(t, linked[1].RefundedBy)
+	assert.Empty(t, linked[8].RefundOf, "refunds come after the purchase")
+	assert.Empty(t, linked[9].RefundOf)
+
+	net, err := parser.NetRefunds(linked)
+	require.NoError(t, err)
+
+	var ids, amounts []string
+	for _, t := range net {
+		ids = append(ids, t.ID)
+		amounts = append(amounts, t.Amount)
+	}
+
+	assert.Equal(t, []string{"shoes-other-card", "market", "early", "early-refund", "payment"}, ids)
+	assert.Equal(t, "-100,00", amounts[1])
+}
@@ -0,0 +1,214 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"git.home/c6bank-transactions/internal/qif"
+)
+
+// SplitMode tells how outputs are split per card.
+type SplitMode string
+
+const (
+	NoSplit       SplitMode = ""
+	SplitFiles    SplitMode = "files"    // one file per card
+	SplitAccounts SplitMode = "accounts" // one QIF with an !Account block per card
+)
+
+const (
+	unknownCard        = "unknown"
+	bankAccountName    = "C6 Conta Corrente"
+	bankAccountDesc    = "Conta corrente C6 Bank"
+	cardAccountDescFmt = "Cartão C6 final %s"
+)
+
+var (
+	ErrInvalidSplit = errors.New("invalid split mode")
+
+	SplitModes = []SplitMode{SplitFiles, SplitAccounts}
+)
+
+// ParseSplitMode validates s, an empty string meaning NoSplit.
+func ParseSplitMode(s string) (SplitMode, error) {
+	mode := SplitMode(s)
+	if mode != NoSplit && !slices.Contains(SplitModes, mode) {
+		return NoSplit, fmt.Errorf("%w %q, use files or accounts", ErrInvalidSplit, s)
+	}
+
+	return mode, nil
+}
+
+// CardGroup holds the transactions of a single card.
+type CardGroup struct {
+	Card         string
+	CardName     string
+	Transactions []Transaction
+}
+
+// AccountName names the card's account, e.g. "C6 1234 DANILO".
+func (g CardGroup) AccountName() string {
+	name := "C6 " + g.Label()
+	if g.CardName != "" {
+		name += " " + g.CardName
+	}
+
+	return name
+}
+
+// Description describes the card's account, e.g. "Cartão C6 final 1234 (DANILO)".
+func (g CardGroup) Description() string {
+	description := fmt.Sprintf(cardAccountDescFmt, g.Label())
+	if g.CardName != "" {
+		description += " (" + g.CardName + ")"
+	}
+
+	return description
+}
+
+// Label is the card ending, or "unknown" for transactions without a card.
+func (g CardGroup) Label() string {
+	if g.Card == "" {
+		return unknownCard
+	}
+
+	return g.Card
+}
+
+// GroupByCard groups transactions by card ending, keeping their order within
+// each group. Groups are sorted by card, transactions without a card last.
+func GroupByCard(transactions []Transaction) []CardGroup {
+	var groups []CardGroup
+
+	for _, t := range transactions {
+		i := slices.IndexFunc(groups, func(g CardGroup) bool { return g.Card == t.Card })
+		if i == -1 {
+			groups = append(groups, CardGroup{Card: t.Card})
+			i = len(groups) - 1
+		}
+
+		if groups[i].CardName == "" {
+			groups[i].CardName = t.CardName
+		}
+
+		groups[i].Transactions = append(groups[i].Transactions, t)
+	}
+
+	slices.SortStableFunc(groups, func(a, b CardGroup) int {
+		switch {
+		case a.Card == b.Card:
+			return 0
+		case a.Card == "":
+			return 1
+		case b.Card == "":
+			return -1
+		}
+
+		return strings.Compare(a.Card, b.Card)
+	})
+
+	return groups
+}
+
+// SplitName adds the card label to name before its extension, so
+// "fatura.qif" becomes "fatura-1234.qif".
+func SplitName(name string, group CardGroup) string {
+	ext := filepath.Ext(name)
+
+	return strings.TrimSuffix(name, ext) + "-" + group.Label() + ext
+}
+
+// ExportAccounts renders a QIF with one credit card account per card.
+// Transactions without a card belong to an account of qtype, so a bank
+// statement goes into the checking account alongside the cards. Options are
+// the ones of Export: WithFuture(FutureExclude) leaves projected installments
+// out, and WithBalances opens the checking account with its balance.
+func ExportAccounts(qtype qif.QIFType, groups []CardGroup, opts ...Option) (io.Reader, error) {
+	o := newOptions(opts)
+	accounts := make([]qif.Account, 0, len(groups))
+
+	for _, g := range groups {
+		transactions := g.Transactions
+		if o.future == FutureExclude {
+			transactions, _ = SeparateFuture(transactions)
+		}
+
+		transactions = slices.Clone(transactions)
+		AssignIDs(transactions)
+
+		account := qif.Account{
+			Name:         g.AccountName(),
+			Type:         qif.CreditCardType,
+			Description:  g.Description(),
+			Transactions: transactionsToQIF(transactions),
+		}
+
+		if g.Card == "" {
+			account.Type = qtype
+		}
+
+		if g.Card == "" && qtype == qif.BankType {
+			account.Name = bankAccountName
+			account.Description = bankAccountDesc
+			account.Transactions = append(o.openingQIF(transactions), account.Transactions...)
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return qif.ParseAccounts(accounts)
+}
+
+// OutputFile is a file rendered by Render.
+type OutputFile struct {
+	Name    string
+	Content io.Reader
+	// Transactions is how many transactions the file holds
+	Transactions int
+	// Scheduled tells the file holds the projected installments separated
+	// by WithFuture(FutureSeparate)
+	Scheduled bool
+}
+
+func filesByCard(format Format, qtype qif.QIFType, name string, groups []CardGroup, opts ...Option) ([]OutputFile, error) {
+	files := make([]OutputFile, 0, len(groups))
+
+	for _, g := range groups {
+		r, err := Export(format, qtype, g.Transactions, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, OutputFile{Name: SplitName(name, g), Content: r, Transactions: len(g.Transactions)})
+	}
+
+	return files, nil
+}
+
+func zipFiles(files []OutputFile) (io.Reader, error) {
+	buf := new(bytes.Buffer)
+	archive := zip.NewWriter(buf)
+
+	for _, f := range files {
+		w, err := archive.Create(f.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.Copy(w, f.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
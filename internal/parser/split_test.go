@@ -0,0 +1,189 @@
+package parser_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupByCard(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	groups := parser.GroupByCard([]parser.Transaction{
+		{Date: date, Payee: "A", Card: "5678", CardName: "MARIA"},
+		{Date: date, Payee: "B"},
+		{Date: date, Payee: "C", Card: "1234", CardName: "DANILO"},
+		{Date: date, Payee: "D", Card: "5678", CardName: "MARIA"},
+	})
+
+	require.Len(t, groups, 3)
+
+	assert.Equal(t, "1234", groups[0].Card)
+	assert.Equal(t, "C6 1234 DANILO", groups[0].AccountName())
+	assert.Len(t, groups[0].Transactions, 1)
+
+	assert.Equal(t, "5678", groups[1].Card)
+	assert.Equal(t, "A", groups[1].Transactions[0].Payee)
+	assert.Equal(t, "D", groups[1].Transactions[1].Payee)
+
+	assert.Equal(t, "", groups[2].Card)
+	assert.Equal(t, "C6 unknown", groups[2].AccountName())
+	assert.Equal(t, "out-unknown.qif", parser.SplitName("out.qif", groups[2]))
+}
+
+func TestParseSplitMode(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"", "files", "accounts"} {
+		mode, err := parser.ParseSplitMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, parser.SplitMode(s), mode)
+	}
+
+	_, err := parser.ParseSplitMode("cards")
+	require.ErrorIs(t, err, parser.ErrInvalidSplit)
+}
+
+func TestParse_SplitFiles(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/Fatura_2026-01-15.csv")
+	require.NoError(t, err)
+	defer f.Close()
+
+	r, name, _, err := parser.Parse("Fatura_2026-01-15.csv", f, 0, "", false, parser.WithSplit(parser.SplitFiles))
+	require.NoError(t, err)
+	assert.Equal(t, "Fatura_2026-01-15.zip", name)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 2)
+
+	assert.Equal(t, "Fatura_2026-01-15-1234.qif", archive.File[0].Name)
+	assert.Equal(t, "Fatura_2026-01-15-5678.qif", archive.File[1].Name)
+
+	content, err := archive.File[0].Open()
+	require.NoError(t, err)
+	defer content.Close()
+
+	out, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "PMERCADO EXTRA\n")
+	assert.NotContains(t, string(out), "AMAZON BR")
+}
+
+func TestExportAccounts(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	groups := parser.GroupByCard([]parser.Transaction{
+		{Date: date, Payee: "A", Amount: "-1,00", Card: "5678", CardName: "MARIA"},
+		{Date: date, Payee: "B", Amount: "-2,00", Card: "1234", CardName: "DANILO"},
+	})
+
+	r, err := parser.ExportAccounts(qif.CreditCardType, groups)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	output := string(data)
+	assert.Contains(t, output, "!Option:AutoSwitch")
+	assert.Contains(t, output, "!Account\nNC6 1234 DANILO\nTCCard\nDCartão C6 final 1234 (DANILO)\n^\n!Type:CCard\n")
+	assert.Contains(t, output, "!Account\nNC6 5678 MARIA\nTCCard\nDCartão C6 final 5678 (MARIA)\n^\n!Type:CCard\n")
+}
+
+func TestExportAccounts_BankAndCards(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	groups := parser.GroupByCard([]parser.Transaction{
+		{Date: date, Payee: "PIX", Amount: "-10,00"},
+		{Date: date, Payee: "A", Amount: "-1,00", Card: "1234", Category: "Restaurante"},
+		{Date: date, Payee: "B", Amount: "-2,00", Card: "1234", Future: true},
+	})
+
+	r, err := parser.ExportAccounts(qif.BankType, groups)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	output := string(data)
+	assert.Contains(t, output, "!Account\nNC6 Conta Corrente\nTBank\nDConta corrente C6 Bank\n^\n!Type:Bank\n")
+	assert.Contains(t, output, "!Account\nNC6 1234\nTCCard\nDCartão C6 final 1234\n^\n!Type:CCard\n")
+	assert.Contains(t, output, "PA\nT-1,00\nC*\nLRestaurante\n^")
+	assert.Contains(t, output, "PB\nT-2,00\n^")
+}
+
+func TestExportAccounts_Options(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	groups := parser.GroupByCard([]parser.Transaction{
+		{Date: date, Payee: "PIX", Amount: "-10,00"},
+		{Date: date, Payee: "A", Amount: "-1,00", Card: "1234"},
+		{Date: date, Payee: "B", Amount: "-2,00", Card: "1234", Future: true},
+	})
+
+	r, err := parser.ExportAccounts(qif.BankType, groups, parser.WithFuture(parser.FutureExclude),
+		parser.WithBalances(parser.Balance{Date: date, Cents: 50000}, parser.Balance{Date: date, Cents: 49000}))
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	output := string(data)
+	assert.Contains(t, output, "D01/03/2026\nPOpening Balance\nT500,00\n")
+	assert.Contains(t, output, "PA\n")
+	assert.NotContains(t, output, "PB\n")
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "A", Amount: "-1,00", Card: "5678"},
+		{Date: date, Payee: "B", Amount: "-2,00", Card: "1234"},
+		{Date: date, Payee: "C", Amount: "-3,00", Card: "1234", Future: true},
+	}
+
+	files, err := parser.Render(parser.FormatCSV, qif.CreditCardType, "fatura.csv", transactions,
+		parser.WithSplit(parser.SplitFiles), parser.WithFuture(parser.FutureSeparate))
+	require.NoError(t, err)
+
+	var got []string
+	for _, f := range files {
+		got = append(got, fmt.Sprintf("%s %d %t", f.Name, f.Transactions, f.Scheduled))
+	}
+
+	assert.Equal(t, []string{
+		"fatura-1234.csv 1 false",
+		"fatura-5678.csv 1 false",
+		"fatura-scheduled-1234.csv 1 true",
+	}, got)
+
+	files, err = parser.Render(parser.FormatQIF, qif.CreditCardType, "", transactions, parser.WithFuture(parser.FutureExclude))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, 2, files[0].Transactions)
+}
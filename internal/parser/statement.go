@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// BalanceKind tells which balance a statement line shows.
+type BalanceKind string
+
+const (
+	OpeningBalance BalanceKind = "opening" // "Saldo anterior"
+	DailyBalance   BalanceKind = "daily"   // "Saldo do dia"
+	ClosingBalance BalanceKind = "closing" // "Saldo final"
+)
+
+// openingBalancePayee is the payee finance apps expect on the transaction
+// setting the opening balance of a QIF account.
+const openingBalancePayee = "Opening Balance"
+
+var regexBalance = regexp.MustCompile(`(?i)^\s*(?:(\d{2}/\d{2}/\d{4})\s+)?saldo\s+(anterior|inicial|do dia|final|atual)\b.*?(-?)\s*(?:R\$\s*)?(-?[0-9.]+,\d{2})\s*([CD])?\s*$`)
+
+var balanceKinds = map[string]BalanceKind{
+	"anterior": OpeningBalance,
+	"inicial":  OpeningBalance,
+	"do dia":   DailyBalance,
+	"final":    ClosingBalance,
+	"atual":    ClosingBalance,
+}
+
+// Balance is a balance printed in an account statement.
+type Balance struct {
+	Kind  BalanceKind `json:"kind"`
+	Date  time.Time   `json:"date"` // zero when the line has no date
+	Cents int64       `json:"balance"`
+	Row   int         `json:"row"` // how many transaction rows come before it
+}
+
+// BalanceDiscrepancy is a printed balance the transactions before it don't
+// add up to, as a row was skipped or misread.
+type BalanceDiscrepancy struct {
+	Balance  Balance `json:"balance"`
+	Computed int64   `json:"computed"` // the previous balance plus the rows since it
+}
+
+// Difference is what the rows between the previous balance and this one
+// are missing: positive for a missing credit, negative for a missing debit.
+func (d BalanceDiscrepancy) Difference() int64 {
+	return d.Balance.Cents - d.Computed
+}
+
+func (d BalanceDiscrepancy) String() string {
+	return fmt.Sprintf("%s balance %s is %s, but the rows before it add up to %s (%s missing)",
+		d.Balance.Kind, balanceDate(d.Balance), FormatCents(d.Balance.Cents), FormatCents(d.Computed), FormatCents(d.Difference()))
+}
+
+func balanceDate(b Balance) string {
+	if b.Date.IsZero() {
+		return fmt.Sprintf("after row %d", b.Row)
+	}
+
+	return "on " + b.Date.Format(dateFormat)
+}
+
+// PDFKind tells the PDFs C6 sends apart.
+type PDFKind string
+
+const (
+	PDFStatement PDFKind = "statement" // account statement ("extrato")
+	PDFInvoice   PDFKind = "invoice"   // card invoice ("fatura")
+)
+
+// Statement is what a PDF shows: the balances of an account statement or the
+// total of a card invoice.
+type Statement struct {
+	Kind          PDFKind
+	Transactions  []Transaction
+	Balances      []Balance
+	Discrepancies []BalanceDiscrepancy
+	Totals        []InvoiceTotal
+	Skipped       []SkippedRow
+}
+
+// ScanPDF reads an account statement or a card invoice PDF, telling them
+// apart by the invoice's total to pay. Skipped are the rows that look like
+// transactions but could not be read.
+func ScanPDF(file io.ReaderAt, size int64, password string) (Statement, error) {
+	rows, err := readPDF(file, size, password)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	if isInvoice(rows) {
+		return scanInvoice(rows)
+	}
+
+	return scanStatement(rows), nil
+}
+
+// scanStatement reads the transactions and balances of an account statement
+// and checks that every balance is the previous one plus the rows between
+// them.
+func scanStatement(rows []pdfRow) Statement {
+	lines, balances, skipped := scanStatementRows(rows)
+
+	transactions, invalid := linesToTypedTransactions(lines)
+	AssignIDs(withSource(transactions, SourceStatement))
+	Classify(transactions)
+
+	return Statement{
+		Kind:          PDFStatement,
+		Transactions:  transactions,
+		Balances:      balances,
+		Discrepancies: checkBalances(lines, balances),
+		Skipped:       append(skipped, invalid...),
+	}
+}
+
+// StatementBalances returns the first opening balance and the last closing
+// one, falling back to the first and last balances printed.
+func StatementBalances(balances []Balance) (opening, closing Balance, ok bool) {
+	if len(balances) == 0 {
+		return Balance{}, Balance{}, false
+	}
+
+	opening, closing = balances[0], balances[len(balances)-1]
+
+	for _, b := range balances {
+		if b.Kind == OpeningBalance {
+			opening = b
+			break
+		}
+	}
+
+	for i := len(balances) - 1; i >= 0; i-- {
+		if balances[i].Kind == ClosingBalance {
+			closing = balances[i]
+			break
+		}
+	}
+
+	return opening, closing, true
+}
+
+func parseBalance(line string) (Balance, bool) {
+	m := regexBalance.FindStringSubmatch(line)
+	if m == nil {
+		return Balance{}, false
+	}
+
+	cents, err := ParseAmount(m[4])
+	if err != nil {
+		return Balance{}, false
+	}
+
+	if (m[3] == "-" || m[5] == "D") && cents > 0 {
+		cents = -cents
+	}
+
+	balance := Balance{Kind: balanceKinds[strings.ToLower(m[2])], Cents: cents}
+
+	if m[1] != "" {
+		balance.Date, _ = time.Parse(dateFormat, m[1])
+	}
+
+	return balance, true
+}
+
+// checkBalances walks the balances in order, comparing each one with the
+// previous balance plus the rows between them. The walk restarts from every
+// printed balance, so a skipped row is reported once.
+func checkBalances(lines []Line, balances []Balance) []BalanceDiscrepancy {
+	var discrepancies []BalanceDiscrepancy
+
+	for i := 1; i < len(balances); i++ {
+		previous, balance := balances[i-1], balances[i]
+		computed := previous.Cents
+
+		for _, l := range lines[previous.Row:balance.Row] {
+			if cents, err := ParseAmount(l[3]); err == nil {
+				computed += cents
+			}
+		}
+
+		if computed != balance.Cents {
+			discrepancies = append(discrepancies, BalanceDiscrepancy{Balance: balance, Computed: computed})
+		}
+	}
+
+	return discrepancies
+}
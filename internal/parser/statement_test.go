@@ -0,0 +1,169 @@
+package parser_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/ledongthuc/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// word lays s out at x, 5 points wide per character.
+func word(x float64, s string) parser.PDFWord {
+	return parser.PDFWord{X: x, W: 5 * float64(utf8.RuneCountInString(s)), S: s}
+}
+
+func row(page int, words ...parser.PDFWord) parser.PDFRow {
+	return parser.PDFRow{Page: page, Words: words}
+}
+
+// tableRow lays out a statement row under the header of statementRows, with
+// the value right-aligned.
+func tableRow(page int, date, description, document, value, kind string) parser.PDFRow {
+	r := row(page, word(40, date))
+
+	x := 100.0
+	for _, w := range strings.Fields(description) {
+		r.Words = append(r.Words, word(x, w))
+		x += r.Words[len(r.Words)-1].W + 5
+	}
+
+	for _, w := range []parser.PDFWord{word(350, document), word(445-5*float64(len(value)), value), word(480, kind)} {
+		if w.S != "" {
+			r.Words = append(r.Words, w)
+		}
+	}
+
+	return r
+}
+
+func statementRows(closing string) []parser.PDFRow {
+	return []parser.PDFRow{
+		row(1, word(40, "Extrato"), word(80, "de"), word(95, "conta"), word(125, "corrente")),
+		row(1, word(40, "Data"), word(100, "Descrição"), word(350, "Documento"), word(420, "Valor"), word(480, "C/D")),
+		row(1, word(100, "Saldo"), word(130, "anterior"), word(385, "R$"), word(405, "1.000,00")),
+		tableRow(1, "02/03/2026", "PIX recebido - Fulano de Tal", "123456789012", "500,00", "C"),
+		tableRow(1, "02/03/2026", "Pão de Açúcar - Loja-Centro", "123456789013", "300,00", "D"),
+		row(1, word(40, "02/03/2026"), word(100, "Saldo"), word(130, "do"), word(145, "dia"), word(405, "1.200,00")),
+		tableRow(1, "03/03/2026", "TED enviada", "123456789014", "", "D"),
+		row(1, word(250, "Página"), word(285, "1"), word(295, "de"), word(310, "2")),
+		tableRow(2, "03/03/2026", "TED enviada - Ciclano", "123456789015", "250,00", "D"),
+		row(2, word(100, "Saldo"), word(130, "final"), word(385, "R$"), word(415, closing)),
+	}
+}
+
+func TestScanStatementRows(t *testing.T) {
+	t.Parallel()
+
+	lines, balances, skipped := parser.ScanStatementRows(statementRows("950,00"))
+	assert.Equal(t, []parser.Line{
+		{"02/03/2026", "PIX recebido", "Fulano de Tal", "500,00"},
+		{"02/03/2026", "Pão de Açúcar", "Loja-Centro", "-300,00"},
+		{"03/03/2026", "TED enviada", "Ciclano", "-250,00"},
+	}, lines)
+
+	assert.Equal(t, []parser.Balance{
+		{Kind: parser.OpeningBalance, Cents: 100000, Row: 0},
+		{Kind: parser.DailyBalance, Date: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Cents: 120000, Row: 2},
+		{Kind: parser.ClosingBalance, Cents: 95000, Row: 3},
+	}, balances)
+
+	assert.Equal(t, []parser.SkippedRow{
+		{Row: 3, Line: parser.Line{"03/03/2026", "TED enviada", "", "D"}, Reason: "page 1: no amount"},
+	}, skipped)
+
+	assert.Empty(t, parser.CheckBalances(lines, balances))
+
+	opening, closing, ok := parser.StatementBalances(balances)
+	require.True(t, ok)
+	assert.Equal(t, balances[0], opening)
+	assert.Equal(t, balances[2], closing)
+}
+
+func TestScanStatementRows_WithoutHeader(t *testing.T) {
+	t.Parallel()
+
+	lines, _, skipped := parser.ScanStatementRows([]parser.PDFRow{
+		row(1, word(10, "05/03/2026"), word(70, "Padaria"), word(110, "São"), word(130, "João"), word(300, "123456789016"), word(420, "12,50"), word(480, "D")),
+		row(1, word(10, "06/03/2026"), word(70, "Estorno"), word(110, "-"), word(120, "loja"), word(420, "10,00")),
+		row(1, word(10, "07/03/2026"), word(70, "Tarifa"), word(480, "D")),
+		row(1, word(10, "Emitido"), word(50, "em"), word(70, "08/03/2026")),
+	})
+
+	assert.Equal(t, []parser.Line{
+		{"05/03/2026", "Padaria São João", "", "-12,50"},
+		{"06/03/2026", "Estorno", "loja", "10,00"},
+	}, lines)
+
+	require.Len(t, skipped, 1)
+	assert.Equal(t, "page 1: no amount", skipped[0].Reason)
+}
+
+func TestCheckBalances(t *testing.T) {
+	t.Parallel()
+
+	lines, balances, _ := parser.ScanStatementRows(statementRows("900,00"))
+
+	discrepancies := parser.CheckBalances(lines, balances)
+	require.Len(t, discrepancies, 1, "the closing balance is 50,00 short of the rows")
+	assert.Equal(t, parser.ClosingBalance, discrepancies[0].Balance.Kind)
+	assert.Equal(t, int64(95000), discrepancies[0].Computed)
+	assert.Equal(t, int64(-5000), discrepancies[0].Difference())
+	assert.Equal(t, "closing balance after row 3 is 900.00, but the rows before it add up to 950.00 (-50.00 missing)", discrepancies[0].String())
+}
+
+func TestPDFWords(t *testing.T) {
+	t.Parallel()
+
+	glyph := func(x float64, s string) pdf.Text {
+		return pdf.Text{X: x, W: 5, S: s, FontSize: 10}
+	}
+
+	words := parser.PDFWords(pdf.TextHorizontal{
+		glyph(15, "ã"), glyph(10, "P"), glyph(20, "o"),
+		glyph(25, " "),
+		glyph(30, "d"), glyph(36, "e"),
+		glyph(60, "R$ 1,00"),
+	})
+
+	assert.Equal(t, []parser.PDFWord{
+		{X: 10, W: 15, S: "Pão"},
+		{X: 30, W: 11, S: "de"},
+		{X: 60, W: 5, S: "R$ 1,00"},
+	}, words)
+}
+
+func TestExport_Balances(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	transactions := []parser.Transaction{{ID: "1", Date: date, Payee: "PIX", Amount: "500,00"}}
+	opening := parser.Balance{Kind: parser.OpeningBalance, Cents: 100000}
+	closing := parser.Balance{Kind: parser.ClosingBalance, Cents: 150000, Date: date.AddDate(0, 0, 1)}
+
+	export := func(format parser.Format) string {
+		r, err := parser.Export(format, qif.BankType, transactions, parser.WithBalances(opening, closing))
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	out := export(parser.FormatQIF)
+	assert.True(t, strings.HasPrefix(out, "!Type:Bank\nN"), out)
+	assert.Contains(t, out, "\nD02/03/2026\nPOpening Balance\nT1.000,00\nCX\nL[C6 Conta Corrente]\n^")
+
+	got, err := parser.ReadQIF(strings.NewReader(out))
+	require.NoError(t, err)
+	assert.Len(t, got, 1, "the opening balance is not read back as a transaction")
+
+	assert.Contains(t, export(parser.FormatOFX), "</BANKTRANLIST>\n<LEDGERBAL>\n<BALAMT>1500.00\n<DTASOF>20260303\n</LEDGERBAL>")
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTotal = errors.New("invalid invoice total")
+
+// regexTotal finds the amount due printed next to the invoice month.
+var regexTotal = regexp.MustCompile(`R\$\s*(\d{1,3}(?:\.\d{3})*,\d{2})`)
+
+// InvoiceTotal is the amount due of an invoice month, as printed by the bank
+// or given by the user.
+type InvoiceTotal struct {
+	Month time.Time `json:"month"`
+	Cents int64     `json:"total"` // positive when there's something to pay
+}
+
+// ParseInvoiceTotal parses "03/2026=1.234,56", the total of the March 2026
+// invoice.
+func ParseInvoiceTotal(s string) (InvoiceTotal, error) {
+	month, amount, ok := strings.Cut(s, "=")
+	if !ok {
+		return InvoiceTotal{}, fmt.Errorf("%w %q, use MM/YYYY=amount", ErrInvalidTotal, s)
+	}
+
+	date, err := time.Parse(refFormat, strings.TrimSpace(month))
+	if err != nil {
+		return InvoiceTotal{}, fmt.Errorf("%w %q: month: %w", ErrInvalidTotal, s, err)
+	}
+
+	cents, err := ParseAmount(strings.TrimSpace(amount))
+	if err != nil {
+		return InvoiceTotal{}, fmt.Errorf("%w %q: %w", ErrInvalidTotal, s, err)
+	}
+
+	return InvoiceTotal{Month: date, Cents: cents}, nil
+}
+
+// TotalCheck compares the transactions billed in an invoice month with the
+// invoice total.
+type TotalCheck struct {
+	Month    time.Time `json:"month"`
+	Expected int64     `json:"expected"` // the invoice total
+	Parsed   int64     `json:"parsed"`   // what the transactions add up to
+	Count    int       `json:"count"`
+	// Extra are the transactions whose amount alone is the excess, most
+	// likely read twice or belonging to another invoice.
+	Extra []Transaction `json:"extra,omitempty"`
+}
+
+// Difference is positive when the transactions add up to more than the
+// total (extra rows) and negative when they fall short (missing rows).
+func (c TotalCheck) Difference() int64 {
+	return c.Parsed - c.Expected
+}
+
+func (c TotalCheck) OK() bool {
+	return c.Difference() == 0
+}
+
+// CheckTotals adds up, for every month with a total, the card transactions
+// billed in that invoice: neither projected nor processing, from its memo's
+// invoice month. Purchases are debits, so what is owed is the opposite of
+// their sum. When several totals are given for a month the last one wins.
+func CheckTotals(transactions []Transaction, totals []InvoiceTotal) ([]TotalCheck, error) {
+	expected := make(map[time.Time]int64)
+	for _, total := range totals {
+		expected[total.Month] = total.Cents
+	}
+
+	checks := make(map[time.Time]*TotalCheck, len(expected))
+	for month, cents := range expected {
+		checks[month] = &TotalCheck{Month: month, Expected: cents}
+	}
+
+	owed := make([]int64, len(transactions))
+
+	for i, t := range transactions {
+		check, ok := checks[invoiceMonth(t)]
+		if !ok || t.Future || t.Processing || t.Source == SourceStatement {
+			continue
+		}
+
+		cents, err := ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		owed[i] = -cents
+		check.Parsed += owed[i]
+		check.Count++
+	}
+
+	result := make([]TotalCheck, 0, len(checks))
+
+	for _, check := range checks {
+		if diff := check.Difference(); diff > 0 {
+			for i, t := range transactions {
+				if owed[i] == diff && invoiceMonth(t).Equal(check.Month) {
+					check.Extra = append(check.Extra, t)
+				}
+			}
+		}
+
+		result = append(result, *check)
+	}
+
+	slices.SortFunc(result, func(a, b TotalCheck) int {
+		return a.Month.Compare(b.Month)
+	})
+
+	return result, nil
+}
+
+// parseTotal finds the invoice total in the OCR text of a screenshot's month
+// region, when the screenshot shows it.
+func parseTotal(month time.Time, text string) []InvoiceTotal {
+	m := regexTotal.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+
+	cents, err := ParseAmount(m[1])
+	if err != nil {
+		return nil
+	}
+
+	return []InvoiceTotal{{Month: time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC), Cents: cents}}
+}
@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInvoiceTotal(t *testing.T) {
+	t.Parallel()
+
+	total, err := parser.ParseInvoiceTotal("03/2026=1.234,56")
+	require.NoError(t, err)
+	assert.Equal(t, parser.InvoiceTotal{Month: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Cents: 123456}, total)
+
+	for _, s := range []string{"1.234,56", "2026-03=1,00", "03/2026=abc"} {
+		_, err := parser.ParseInvoiceTotal(s)
+		assert.ErrorIs(t, err, parser.ErrInvalidTotal, s)
+	}
+}
+
+func TestCheckTotals(t *testing.T) {
+	t.Parallel()
+
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := march.AddDate(0, 1, 0)
+
+	transactions := []parser.Transaction{
+		{Date: march, Payee: "MERCADO", Memo: "1234 03/2026", Amount: "-100,00"},
+		{Date: march, Payee: "PADARIA", Memo: "1234 03/2026", Amount: "-9,00"},
+		{Date: march, Payee: "PADARIA", Memo: "1234 03/2026", Amount: "-9,00"},
+		{Date: march, Payee: "ESTORNO", Memo: "1234 03/2026", Amount: "20,00"},
+		{Date: march, Payee: "LOJA", Memo: "2/2 1234 04/2026", Amount: "-50,00", Future: true},
+		{Date: march, Payee: "UBER", Memo: "1234 03/2026", Amount: "-15,00", Processing: true},
+		{Date: april, Payee: "MERCADO", Memo: "1234 04/2026", Amount: "-80,00"},
+	}
+
+	checks, err := parser.CheckTotals(transactions, []parser.InvoiceTotal{
+		{Month: april, Cents: 13000},
+		{Month: march, Cents: 8900},
+	})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+
+	assert.Equal(t, march, checks[0].Month)
+	assert.Equal(t, int64(9800), checks[0].Parsed)
+	assert.Equal(t, 4, checks[0].Count)
+	assert.Equal(t, int64(900), checks[0].Difference(), "a row was read twice")
+	require.Len(t, checks[0].Extra, 2)
+	assert.Equal(t, "PADARIA", checks[0].Extra[0].Payee)
+
+	assert.Equal(t, int64(-5000), checks[1].Difference(), "a row is missing")
+	assert.Empty(t, checks[1].Extra)
+	assert.False(t, checks[1].OK())
+
+	_, err = parser.CheckTotals([]parser.Transaction{{Memo: "03/2026", Amount: "x"}}, []parser.InvoiceTotal{{Month: march}})
+	assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+}
+
+func TestCheckTotals_Screenshot(t *testing.T) {
+	t.Parallel()
+
+	cache := screenshotCache(t, "testdata/IMG_0420.PNG", "Fatura de janeiro Aberta R$ 167,91")
+
+	results := parser.ParseFiles([]string{"testdata/IMG_0420.PNG"}, 1, parser.WithOCRCache(cache))
+	require.NoError(t, results[0].Err)
+	require.Len(t, results[0].Totals, 1)
+
+	checks, err := parser.CheckTotals(results[0].Transactions, results[0].Totals)
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	assert.True(t, checks[0].OK(), "parsed %d", checks[0].Parsed)
+
+	// the same screenshot read twice
+	twice := append(results[0].Transactions, results[0].Transactions...)
+
+	checks, err = parser.CheckTotals(twice, results[0].Totals)
+	require.NoError(t, err)
+	assert.Equal(t, int64(16791), checks[0].Difference())
+	assert.Len(t, checks[0].Extra, 2)
+}
+
+func TestParseTotal(t *testing.T) {
+	t.Parallel()
+
+	month := time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)
+
+	assert.Equal(t, []parser.InvoiceTotal{{Month: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Cents: 123456}},
+		parser.ParseTotal(month, "fevereiro março abril\nFatura atual R$ 1.234,56\n"))
+	assert.Nil(t, parser.ParseTotal(month, "fevereiro março abril\n"))
+}
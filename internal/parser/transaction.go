@@ -2,18 +2,37 @@ package parser
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type Transaction struct {
-	Date        time.Time
-	Payee       string
-	Memo        string
-	Amount      string
-	Installment bool
-	Future      bool
+	ID          string    `json:"id"` // see AssignIDs
+	Date        time.Time `json:"date"`
+	Payee       string    `json:"payee"`
+	Memo        string    `json:"memo"`
+	Amount      string    `json:"amount"`
+	Card        string    `json:"card,omitempty"`      // card ending, e.g. "1234"
+	CardName    string    `json:"card_name,omitempty"` // cardholder name printed on the card
+	Category    string    `json:"category,omitempty"`
+	Installment bool      `json:"installment"`
+	Future      bool      `json:"future"`
+	Processing  bool      `json:"processing,omitempty"` // shown as "Em processamento", not posted yet
+	Source      Source    `json:"source,omitempty"`
+
+	Kind        Kind   `json:"kind,omitempty"`        // see Classify
+	Counterpart string `json:"counterpart,omitempty"` // who sent or received a transfer
+	Document    string `json:"document,omitempty"`    // counterpart's CPF or CNPJ, masked
+	Transfer    string `json:"transfer,omitempty"`    // the other account of a transfer, see LinkInvoicePayments
+	TransferID  string `json:"transfer_id,omitempty"` // ID of the other side of the transfer
+	RefundOf    string `json:"refund_of,omitempty"`   // ID of the purchase a refund gives back, see MatchRefunds
+	RefundedBy  string `json:"refunded_by,omitempty"` // ID of the refund of a purchase
+
+	ForeignAmount string  `json:"foreign_amount,omitempty"` // US$ value of international purchases
+	ExchangeRate  string  `json:"exchange_rate,omitempty"`  // R$ per US$
+	Splits        []Split `json:"splits,omitempty"`         // see SplitCosts
 }
 
 func (t *Transaction) ParseDate(ct CurrentTime, date string) error {
@@ -62,3 +81,39 @@ func (t *Transaction) ParseDate(ct CurrentTime, date string) error {
 func (ts Transaction) CSVLine() []string {
 	return []string{ts.Date.Format(dateFormat), ts.Payee, ts.Memo, ts.Amount}
 }
+
+// withSource sets the source of every transaction.
+func withSource(transactions []Transaction, source Source) []Transaction {
+	for i := range transactions {
+		transactions[i].Source = source
+	}
+
+	return transactions
+}
+
+var regexMemoReference = regexp.MustCompile(`(\d{2})/(\d{4})$`)
+
+// InstallmentOf returns the installment number and count of an installment
+// memo such as "2/10 1234 03/2026".
+func (ts Transaction) InstallmentOf() (current, total int, ok bool) {
+	m := regexMemoInstallment.FindStringSubmatch(ts.Memo)
+	if m == nil {
+		return 0, 0, false
+	}
+
+	current, _ = strconv.Atoi(m[1])
+	total, _ = strconv.Atoi(m[2])
+
+	return current, total, total > 0
+}
+
+// Reference returns the invoice month at the end of the memo ("1234 03/2026"),
+// the month the transaction is billed in.
+func (ts Transaction) Reference() (time.Time, bool) {
+	reference, err := time.Parse(refFormat, regexMemoReference.FindString(ts.Memo))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return reference, true
+}
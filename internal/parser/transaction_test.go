@@ -60,3 +60,22 @@ func TestTransaction_CSVLine(t *testing.T) {
 	csv := ts.CSVLine()
 	assert.Equal(t, []string{"26/12/1985", "Payee", "Memo", "123.45"}, csv)
 }
+
+func TestTransaction_InstallmentOf(t *testing.T) {
+	t.Parallel()
+
+	current, total, ok := parser.Transaction{Memo: "2/10 1234 03/2026"}.InstallmentOf()
+	assert.True(t, ok)
+	assert.Equal(t, 2, current)
+	assert.Equal(t, 10, total)
+
+	_, _, ok = parser.Transaction{Memo: "1234 03/2026"}.InstallmentOf()
+	assert.False(t, ok)
+
+	reference, ok := parser.Transaction{Memo: "2/10 1234 03/2026"}.Reference()
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), reference)
+
+	_, ok = parser.Transaction{Memo: "1234"}.Reference()
+	assert.False(t, ok)
+}
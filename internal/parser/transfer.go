@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+)
+
+// transferDays is how many days apart the invoice payment may show in the
+// statement and in the invoice.
+const transferDays = 5
+
+// regexCardPayment finds the credit of a payment in the invoice, as in
+// "Inclusao de Pagamento".
+var regexCardPayment = regexp.MustCompile(`\b(PAGAMENTO|PAGTO|PGTO)\b`)
+
+// InvoicePayment is an invoice payment from the checking account and the
+// credit it became in the card.
+type InvoicePayment struct {
+	Payment Transaction `json:"payment"`
+	Credit  Transaction `json:"credit"`
+}
+
+// LinkInvoicePayments pairs the invoice payments of the account statement
+// (KindInvoicePayment) with their credit in the invoice: same amount, with
+// a payment payee, up to transferDays apart, the closest date winning. Both
+// become transfers to the other account (Transfer), so finance apps don't
+// count them as an expense and an income: "[C6 1234 FULANO]" in checking and
+// "[C6 Conta Corrente]" in the card, as named by ExportAccounts.
+func LinkInvoicePayments(transactions []Transaction) ([]Transaction, []InvoicePayment) {
+	var (
+		payments []InvoicePayment
+		linked   = make([]bool, len(transactions))
+	)
+
+	transactions = slices.Clone(transactions)
+
+	for i, p := range transactions {
+		cents, err := ParseAmount(p.Amount)
+		if p.Source != SourceStatement || p.Kind != KindInvoicePayment || err != nil || cents >= 0 {
+			continue
+		}
+
+		best, days := -1, 0
+
+		for j, c := range transactions {
+			if linked[j] || c.Source == SourceStatement || c.Future || c.Processing ||
+				!regexCardPayment.MatchString(NormalizePayee(c.Payee)) {
+				continue
+			}
+
+			if credit, err := ParseAmount(c.Amount); err != nil || credit != -cents {
+				continue
+			}
+
+			d := int(abs(int64(c.Date.Sub(p.Date).Hours() / 24)))
+			if d <= transferDays && (best == -1 || d < days) {
+				best, days = j, d
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		linked[best] = true
+		c := &transactions[best]
+
+		transactions[i].Transfer = CardGroup{Card: c.Card, CardName: c.CardName}.AccountName()
+		transactions[i].TransferID = c.ID
+		c.Transfer, c.TransferID = bankAccountName, p.ID
+
+		payments = append(payments, InvoicePayment{Payment: transactions[i], Credit: *c})
+	}
+
+	return transactions, payments
+}
@@ -0,0 +1,67 @@
+package parser_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkInvoicePayments(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	transactions := []parser.Transaction{
+		{ID: "p", Date: day(10), Payee: "PAGTO FATURA", Memo: "CARTAO C6", Amount: "-1.234,56", Source: parser.SourceStatement, Kind: parser.KindInvoicePayment},
+		{ID: "far", Date: day(1), Payee: "Inclusao de Pagamento", Amount: "1.234,56", Card: "1234", Source: parser.SourceInvoice},
+		{ID: "c", Date: day(11), Payee: "Inclusao de Pagamento", Amount: "1.234,56", Card: "1234", CardName: "FULANO", Source: parser.SourceInvoice},
+		{ID: "refund", Date: day(10), Payee: "ESTORNO LOJA", Amount: "1.234,56", Card: "1234", Source: parser.SourceInvoice},
+		{ID: "other", Date: day(10), Payee: "PAGTO FATURA", Amount: "-99,00", Source: parser.SourceStatement, Kind: parser.KindInvoicePayment},
+	}
+
+	linked, payments := parser.LinkInvoicePayments(transactions)
+	require.Len(t, payments, 1)
+	assert.Equal(t, "p", payments[0].Payment.ID)
+	assert.Equal(t, "c", payments[0].Credit.ID)
+
+	assert.Equal(t, "C6 1234 FULANO", linked[0].Transfer)
+	assert.Equal(t, "c", linked[0].TransferID)
+	assert.Equal(t, "C6 Conta Corrente", linked[2].Transfer)
+	assert.Equal(t, "p", linked[2].TransferID)
+
+	for _, i := range []int{1, 3, 4} {
+		assert.Empty(t, linked[i].Transfer, linked[i].ID)
+	}
+
+	assert.Empty(t, transactions[0].Transfer, "the input is left untouched")
+
+	export := func(format parser.Format, qtype qif.QIFType) string {
+		r, err := parser.Export(format, qtype, linked[:3])
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+
+		return string(data)
+	}
+
+	out := export(parser.FormatQIF, qif.BankType)
+	assert.Contains(t, out, "L[C6 1234 FULANO]\n")
+	assert.Contains(t, out, "L[C6 Conta Corrente]\n")
+
+	assert.Equal(t, 2, strings.Count(export(parser.FormatOFX, qif.BankType), "<TRNTYPE>XFER\n"))
+
+	out = export(parser.FormatLedger, qif.BankType)
+	assert.Contains(t, out, "2026-03-10 PAGTO FATURA\n    ; CARTAO C6\n    Assets:C6 Bank:Checking  BRL -1234.56\n    Liabilities:C6 Bank:Credit Card\n")
+	assert.Equal(t, 1, strings.Count(out, "Inclusao de Pagamento"), "only the far credit, not linked, is left")
+
+	out = export(parser.FormatLedger, qif.CreditCardType)
+	assert.Contains(t, out, "2026-03-11 Inclusao de Pagamento\n    Liabilities:C6 Bank:Credit Card  BRL 1234.56\n    Assets:C6 Bank:Checking\n")
+	assert.NotContains(t, out, "PAGTO FATURA")
+}
@@ -3,22 +3,41 @@ package qif
 import (
 	"bytes"
 	"io"
+	"strconv"
 	"text/template"
 
 	"github.com/segmentio/fasthash/fnv1a"
 )
 
 type Transaction struct {
-	ID       uint64
+	ID       string // N field, a hash of Date+Payee+Amount when empty
 	Date     string
 	Amount   string
 	Payee    string
 	Memo     string
 	Category string
+	Cleared  ClearedStatus
+	Splits   []Split
+}
+
+// Split is a part of a transaction amount, written as S, E and $ fields.
+type Split struct {
+	Category string
+	Memo     string
+	Amount   string
 }
 
 type QIFType string
 
+// ClearedStatus is the C field of a transaction, omitted when Uncleared.
+type ClearedStatus string
+
+const (
+	Uncleared  ClearedStatus = ""
+	Cleared    ClearedStatus = "*"
+	Reconciled ClearedStatus = "X"
+)
+
 const (
 	BankType       QIFType = "Bank"  // !Type:Bank  | Cash Flow: Checking & Savings Account
 	CreditCardType QIFType = "CCard" // !Type:CCard | Cash Flow: Credit Card Account
@@ -30,30 +49,105 @@ N{{.ID}}
 D{{.Date}}
 P{{.Payee}}
 T{{.Amount}}
+{{- if .Cleared}}
+C{{.Cleared}}
+{{- end}}
 {{- if .Memo}}
 M{{.Memo}}
 {{- end}}
+{{- if .Category}}
+L{{.Category}}
+{{- end}}
+{{- range .Splits}}
+S{{.Category}}
+{{- if .Memo}}
+E{{.Memo}}
+{{- end}}
+${{.Amount}}
+{{- end}}
+^`
+
+	// accountFmt intentionally starts with a newline
+	accountFmt = `
+!Account
+N{{.Name}}
+T{{.Type}}
+{{- if .Description}}
+D{{.Description}}
+{{- end}}
 ^`
 )
 
-var txTemplate = template.Must(template.New("txFmt").Parse(txFmt))
+var (
+	txTemplate      = template.Must(template.New("txFmt").Parse(txFmt))
+	accountTemplate = template.Must(template.New("accountFmt").Parse(accountFmt))
+)
+
+// Account is one of the accounts written by ParseAccounts.
+type Account struct {
+	Name         string
+	Type         QIFType
+	Description  string
+	Transactions []Transaction
+}
 
 func Parse(qtype QIFType, transactions []Transaction) (io.Reader, error) {
 	buff := new(bytes.Buffer)
+
+	if err := writeTransactions(buff, qtype, transactions); err != nil {
+		return nil, err
+	}
+
+	return buff, nil
+}
+
+// ParseAccounts writes several accounts in a single file: the account list
+// wrapped in AutoSwitch options, followed by an !Account header and the
+// transactions of each account, so they are imported into separate accounts.
+func ParseAccounts(accounts []Account) (io.Reader, error) {
+	buff := new(bytes.Buffer)
+	buff.WriteString("!Option:AutoSwitch")
+
+	for _, account := range accounts {
+		if err := accountTemplate.Execute(buff, account); err != nil {
+			return nil, err
+		}
+	}
+
+	buff.WriteString("\n!Clear:AutoSwitch")
+
+	for _, account := range accounts {
+		if err := accountTemplate.Execute(buff, account); err != nil {
+			return nil, err
+		}
+
+		buff.WriteString("\n")
+
+		if err := writeTransactions(buff, account.Type, account.Transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	return buff, nil
+}
+
+func writeTransactions(buff *bytes.Buffer, qtype QIFType, transactions []Transaction) error {
 	buff.WriteString("!Type:")
 	buff.WriteString(string(qtype))
 
 	for _, tx := range transactions {
-		tx.ID = fnv1a.HashString64(
-			tx.Date + tx.Payee + tx.Amount,
-		)
+		if tx.ID == "" {
+			tx.ID = strconv.FormatUint(fnv1a.HashString64(
+				tx.Date+tx.Payee+tx.Amount,
+			), 10)
+		}
 
 		if err := txTemplate.Execute(buff, tx); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	return buff, nil
+	return nil
 }
 
 // Field  Indicator Explanation
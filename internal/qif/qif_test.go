@@ -13,14 +13,12 @@ import (
 func TestParse(t *testing.T) {
 	transactions := []qif.Transaction{
 		{
-			ID:     0,
 			Date:   "01/01/1111",
 			Amount: "123,45",
 			Payee:  "with memo",
 			Memo:   "memo",
 		},
 		{
-			ID:     1,
 			Date:   "02/02/2222",
 			Amount: "987,65",
 			Payee:  "without memo",
@@ -55,3 +53,104 @@ T987,65
 		})
 	}
 }
+
+func TestParseAccounts(t *testing.T) {
+	accounts := []qif.Account{
+		{
+			Name:        "C6 1234",
+			Type:        qif.CreditCardType,
+			Description: "card 1234",
+			Transactions: []qif.Transaction{
+				{Date: "01/01/1111", Amount: "123,45", Payee: "with memo", Memo: "memo", Cleared: qif.Cleared, Category: "Compras"},
+			},
+		},
+		{
+			Name: "C6 4321",
+			Type: qif.CreditCardType,
+			Transactions: []qif.Transaction{
+				{Date: "02/02/2222", Amount: "987,65", Payee: "without memo"},
+			},
+		},
+	}
+
+	rendered := `!Option:AutoSwitch
+!Account
+NC6 1234
+TCCard
+Dcard 1234
+^
+!Account
+NC6 4321
+TCCard
+^
+!Clear:AutoSwitch
+!Account
+NC6 1234
+TCCard
+Dcard 1234
+^
+!Type:CCard
+N17620778198587367266
+D01/01/1111
+Pwith memo
+T123,45
+C*
+Mmemo
+LCompras
+^
+!Account
+NC6 4321
+TCCard
+^
+!Type:CCard
+N930274905452525158
+D02/02/2222
+Pwithout memo
+T987,65
+^`
+
+	parsed, err := qif.ParseAccounts(accounts)
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(parsed)
+	require.NoError(t, err)
+
+	assert.Equal(t, rendered, string(output))
+}
+
+func TestParse_Splits(t *testing.T) {
+	transactions := []qif.Transaction{
+		{
+			ID:       "c6-42",
+			Date:     "01/01/2026",
+			Amount:   "-58,00",
+			Payee:    "STEAM",
+			Category: "Jogos",
+			Splits: []qif.Split{
+				{Category: "Jogos", Memo: "US$ 10,00 x 5,50", Amount: "-55,00"},
+				{Category: "IOF", Amount: "-3,00"},
+			},
+		},
+	}
+
+	rendered := `!Type:CCard
+Nc6-42
+D01/01/2026
+PSTEAM
+T-58,00
+LJogos
+SJogos
+EUS$ 10,00 x 5,50
+$-55,00
+SIOF
+$-3,00
+^`
+
+	parsed, err := qif.Parse(qif.CreditCardType, transactions)
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(parsed)
+	require.NoError(t, err)
+
+	assert.Equal(t, rendered, string(output))
+}
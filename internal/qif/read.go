@@ -0,0 +1,130 @@
+package qif
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrInvalidQIF = errors.New("invalid QIF")
+
+const (
+	typeHeader    = "!Type:"
+	accountHeader = "!Account"
+	autoSwitch    = "!Option:AutoSwitch"
+	clearSwitch   = "!Clear:AutoSwitch"
+)
+
+// Read parses the accounts written by Parse or ParseAccounts. A file with a
+// single !Type header is returned as one account without a name.
+func Read(r io.Reader) ([]Account, error) {
+	var (
+		accounts []Account
+		header   *Account // last !Account block, waiting for its !Type
+		inList   bool     // inside the AutoSwitch account list
+		inHeader bool
+		tx       Transaction
+	)
+
+	scanner := bufio.NewScanner(r)
+
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		switch {
+		case line == "":
+			continue
+		case line == autoSwitch:
+			inList = true
+			continue
+		case line == clearSwitch:
+			inList = false
+			continue
+		case line == accountHeader:
+			inHeader = true
+			header = &Account{}
+			continue
+		case strings.HasPrefix(line, typeHeader):
+			account := Account{}
+			if header != nil {
+				account = *header
+			}
+
+			account.Type = QIFType(strings.TrimPrefix(line, typeHeader))
+			accounts = append(accounts, account)
+			header = nil
+
+			continue
+		}
+
+		field, value := line[0], line[1:]
+
+		if inHeader {
+			switch field {
+			case 'N':
+				header.Name = value
+			case 'T':
+				header.Type = QIFType(value)
+			case 'D':
+				header.Description = value
+			case '^':
+				inHeader = false
+				if inList {
+					header = nil
+				}
+			}
+
+			continue
+		}
+
+		if len(accounts) == 0 {
+			return nil, fmt.Errorf("%w: line %d: %q before any !Type header", ErrInvalidQIF, lineno, line)
+		}
+
+		switch field {
+		case 'N':
+			tx.ID = value
+		case 'D':
+			tx.Date = value
+		case 'T', 'U':
+			tx.Amount = value
+		case 'P':
+			tx.Payee = value
+		case 'M':
+			tx.Memo = value
+		case 'L':
+			tx.Category = value
+		case 'C':
+			tx.Cleared = ClearedStatus(value)
+		case 'S':
+			tx.Splits = append(tx.Splits, Split{Category: value})
+		case 'E', '$':
+			if len(tx.Splits) == 0 {
+				return nil, fmt.Errorf("%w: line %d: split field without S", ErrInvalidQIF, lineno)
+			}
+
+			split := &tx.Splits[len(tx.Splits)-1]
+			if field == 'E' {
+				split.Memo = value
+			} else {
+				split.Amount = value
+			}
+		case '^':
+			last := &accounts[len(accounts)-1]
+			last.Transactions = append(last.Transactions, tx)
+			tx = Transaction{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("%w: no !Type header", ErrInvalidQIF)
+	}
+
+	return accounts, nil
+}
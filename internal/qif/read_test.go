@@ -0,0 +1,68 @@
+package qif_test
+
+import (
+	"strings"
+	"testing"
+
+	"git.home/c6bank-transactions/internal/qif"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	transactions := []qif.Transaction{
+		{Date: "01/01/2026", Amount: "-58,00", Payee: "STEAM", Memo: "memo", Category: "Jogos", Cleared: qif.Cleared,
+			Splits: []qif.Split{
+				{Category: "Jogos", Memo: "US$ 10,00 x 5,50", Amount: "-55,00"},
+				{Category: "IOF", Amount: "-3,00"},
+			}},
+		{Date: "02/01/2026", Amount: "10,00", Payee: "ESTORNO"},
+	}
+
+	t.Run("single account", func(t *testing.T) {
+		parsed, err := qif.Parse(qif.CreditCardType, transactions)
+		require.NoError(t, err)
+
+		accounts, err := qif.Read(parsed)
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+
+		assert.Equal(t, qif.CreditCardType, accounts[0].Type)
+		assert.Empty(t, accounts[0].Name)
+		require.Len(t, accounts[0].Transactions, 2)
+
+		got := accounts[0].Transactions[0]
+		assert.NotZero(t, got.ID)
+		got.ID = ""
+		assert.Equal(t, transactions[0], got)
+		assert.Equal(t, "ESTORNO", accounts[0].Transactions[1].Payee)
+	})
+
+	t.Run("multiple accounts", func(t *testing.T) {
+		parsed, err := qif.ParseAccounts([]qif.Account{
+			{Name: "C6 Conta Corrente", Type: qif.BankType, Transactions: transactions[1:]},
+			{Name: "C6 1234", Type: qif.CreditCardType, Description: "card", Transactions: transactions[:1]},
+		})
+		require.NoError(t, err)
+
+		accounts, err := qif.Read(parsed)
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+
+		assert.Equal(t, "C6 Conta Corrente", accounts[0].Name)
+		assert.Equal(t, qif.BankType, accounts[0].Type)
+		assert.Len(t, accounts[0].Transactions, 1)
+
+		assert.Equal(t, "C6 1234", accounts[1].Name)
+		assert.Equal(t, "card", accounts[1].Description)
+		assert.Equal(t, "STEAM", accounts[1].Transactions[0].Payee)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := qif.Read(strings.NewReader("D01/01/2026\n^\n"))
+		require.ErrorIs(t, err, qif.ErrInvalidQIF)
+
+		_, err = qif.Read(strings.NewReader(""))
+		require.ErrorIs(t, err, qif.ErrInvalidQIF)
+	})
+}
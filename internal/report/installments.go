@@ -0,0 +1,119 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+// Commitment is what the installments still to be billed add up to on one
+// card in one invoice month. Amounts are in cents, positive.
+type Commitment struct {
+	Month  time.Time `json:"month"`
+	Card   string    `json:"card,omitempty"`
+	Count  int       `json:"count"`
+	Amount int64     `json:"amount"`
+}
+
+// Purchase is an installment purchase with installments still to be billed.
+type Purchase struct {
+	Payee        string    `json:"payee"`
+	Card         string    `json:"card,omitempty"`
+	Installment  int64     `json:"installment"`  // amount of each installment
+	Installments int       `json:"installments"` // total number of installments
+	Remaining    int       `json:"remaining"`
+	Owed         int64     `json:"owed"`
+	Ends         time.Time `json:"ends"` // month of the last installment
+}
+
+// Schedule lists the installment commitments going forward.
+type Schedule struct {
+	Months    []Commitment `json:"months"`
+	Purchases []Purchase   `json:"purchases"`
+	Owed      int64        `json:"owed"`
+}
+
+// Installments builds the schedule of the projected (Future) installments.
+// They are counted in the invoice month of their memo, or in the month of
+// their date when the memo has none. Months are sorted chronologically, then
+// by card; purchases by the month they end.
+func Installments(transactions []parser.Transaction) (Schedule, error) {
+	var (
+		schedule  Schedule
+		months    = make(map[string]*Commitment)
+		purchases = make(map[string]*Purchase)
+	)
+
+	for _, t := range transactions {
+		if !t.Future {
+			continue
+		}
+
+		cents, err := parser.ParseAmount(t.Amount)
+		if err != nil {
+			return Schedule{}, err
+		}
+
+		if cents < 0 {
+			cents = -cents
+		}
+
+		month, ok := t.Reference()
+		if !ok {
+			month = MonthOf(t.Date)
+		}
+
+		key := month.Format("2006-01") + "|" + t.Card
+
+		c, ok := months[key]
+		if !ok {
+			c = &Commitment{Month: month, Card: t.Card}
+			months[key] = c
+		}
+
+		c.Count++
+		c.Amount += cents
+
+		current, total, _ := t.InstallmentOf()
+		// the purchase date keeps apart two purchases of the same amount at
+		// the same store
+		purchased := t.Date.AddDate(0, 1-max(current, 1), 0)
+		key = fmt.Sprintf("%s|%s|%d|%d|%s", t.Payee, t.Card, total, cents, purchased.Format(time.DateOnly))
+
+		p, ok := purchases[key]
+		if !ok {
+			p = &Purchase{Payee: t.Payee, Card: t.Card, Installment: cents, Installments: total}
+			purchases[key] = p
+		}
+
+		p.Remaining++
+		p.Owed += cents
+
+		if month.After(p.Ends) {
+			p.Ends = month
+		}
+
+		schedule.Owed += cents
+	}
+
+	for _, c := range months {
+		schedule.Months = append(schedule.Months, *c)
+	}
+
+	slices.SortFunc(schedule.Months, func(a, b Commitment) int {
+		return cmp.Or(a.Month.Compare(b.Month), cmp.Compare(a.Card, b.Card))
+	})
+
+	for _, p := range purchases {
+		schedule.Purchases = append(schedule.Purchases, *p)
+	}
+
+	slices.SortFunc(schedule.Purchases, func(a, b Purchase) int {
+		return cmp.Or(a.Ends.Compare(b.Ends), cmp.Compare(a.Payee, b.Payee), cmp.Compare(a.Card, b.Card))
+	})
+
+	return schedule, nil
+}
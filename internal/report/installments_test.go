@@ -0,0 +1,49 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallments(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2026, 1, 10, 0, 0, 0, 0, time.Local)
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	transactions := []parser.Transaction{
+		{Date: date, Payee: "LOJA", Amount: "-100,00", Card: "1234", Memo: "1/3 1234 02/2026", Installment: true},
+		{Date: date.AddDate(0, 1, 0), Payee: "LOJA", Amount: "-100,00", Card: "1234", Memo: "2/3 1234 03/2026", Installment: true, Future: true},
+		{Date: date.AddDate(0, 2, 0), Payee: "LOJA", Amount: "-100,00", Card: "1234", Memo: "3/3 1234 04/2026", Installment: true, Future: true},
+		{Date: date.AddDate(0, 1, 0), Payee: "AMAZON", Amount: "-50,00", Card: "4321", Memo: "2/2 4321 03/2026", Installment: true, Future: true},
+		// screenshots show purchases as positive values
+		{Date: date.AddDate(0, 1, 0), Payee: "CINEMA", Amount: "20,00", Memo: "2/2 Parcela", Installment: true, Future: true},
+		{Date: date, Payee: "PADARIA", Amount: "-9,00", Card: "1234"},
+	}
+
+	schedule, err := report.Installments(transactions)
+	require.NoError(t, err)
+
+	assert.Equal(t, []report.Commitment{
+		{Month: month(2), Count: 1, Amount: 2000},
+		{Month: month(3), Card: "1234", Count: 1, Amount: 10000},
+		{Month: month(3), Card: "4321", Count: 1, Amount: 5000},
+		{Month: month(4), Card: "1234", Count: 1, Amount: 10000},
+	}, schedule.Months)
+
+	assert.Equal(t, []report.Purchase{
+		{Payee: "CINEMA", Installment: 2000, Installments: 2, Remaining: 1, Owed: 2000, Ends: month(2)},
+		{Payee: "AMAZON", Card: "4321", Installment: 5000, Installments: 2, Remaining: 1, Owed: 5000, Ends: month(3)},
+		{Payee: "LOJA", Card: "1234", Installment: 10000, Installments: 3, Remaining: 2, Owed: 20000, Ends: month(4)},
+	}, schedule.Purchases)
+
+	assert.Equal(t, int64(27000), schedule.Owed)
+
+	_, err = report.Installments([]parser.Transaction{{Amount: "x", Future: true}})
+	assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+}
@@ -0,0 +1,86 @@
+package report
+
+import (
+	"slices"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+// Month sums the transactions of one calendar month. Amounts are in cents;
+// Debits are negative.
+type Month struct {
+	Month   time.Time `json:"month"`
+	Count   int       `json:"count"`
+	Debits  int64     `json:"debits"`
+	Credits int64     `json:"credits"`
+	// Change is how much Debits changed from the month before, negative
+	// when more was spent; zero for the first month.
+	Change int64 `json:"change"`
+}
+
+// Net is the month balance: credits plus (negative) debits.
+func (m Month) Net() int64 {
+	return m.Credits + m.Debits
+}
+
+// Monthly groups transactions by month, in chronological order, each month
+// compared with the one before it. Projected installments, yet to be
+// billed, and transfers between the account and the card are left out.
+func Monthly(transactions []parser.Transaction) ([]Month, error) {
+	months := make(map[time.Time]*Month)
+
+	for _, t := range transactions {
+		if !counted(t) {
+			continue
+		}
+
+		cents, err := parser.ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		key := MonthOf(t.Date)
+
+		m, ok := months[key]
+		if !ok {
+			m = &Month{Month: key}
+			months[key] = m
+		}
+
+		m.Count++
+
+		if cents < 0 {
+			m.Debits += cents
+		} else {
+			m.Credits += cents
+		}
+	}
+
+	result := make([]Month, 0, len(months))
+	for _, m := range months {
+		result = append(result, *m)
+	}
+
+	slices.SortFunc(result, func(a, b Month) int {
+		return a.Month.Compare(b.Month)
+	})
+
+	for i := 1; i < len(result); i++ {
+		result[i].Change = result[i].Debits - result[i-1].Debits
+	}
+
+	return result, nil
+}
+
+// counted tells whether t moved money in or out: projected installments
+// are yet to be billed, and an invoice payment linked to its card is both a
+// debit of the account and a credit of the card, cancelling out.
+func counted(t parser.Transaction) bool {
+	return !t.Future && t.Transfer == ""
+}
+
+// MonthOf returns the first day of the month of date.
+func MonthOf(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
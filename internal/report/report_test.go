@@ -0,0 +1,36 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthly(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Date: time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local), Amount: "-50,00"},
+		{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local), Amount: "-167,91"},
+		{Date: time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local), Amount: "10,00"},
+		{Date: time.Date(2026, 1, 15, 0, 0, 0, 0, time.Local), Amount: "-1.000,00"},
+		{Date: time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local), Amount: "-250,00", Transfer: "C6 1234"},
+		{Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local), Amount: "-50,00", Installment: true, Future: true},
+	}
+
+	months, err := report.Monthly(transactions)
+	require.NoError(t, err)
+
+	assert.Equal(t, []report.Month{
+		{Month: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Count: 3, Debits: -116791, Credits: 1000},
+		{Month: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Count: 1, Debits: -5000, Change: 111791},
+	}, months)
+	assert.Equal(t, int64(-115791), months[0].Net())
+
+	_, err = report.Monthly([]parser.Transaction{{Amount: "x"}})
+	assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+// Total sums the transactions of one card, category or payee. Amounts are
+// in cents; Debits are negative.
+type Total struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Debits  int64  `json:"debits"`
+	Credits int64  `json:"credits"`
+}
+
+// Net is the balance: credits plus (negative) debits.
+func (t Total) Net() int64 {
+	return t.Credits + t.Debits
+}
+
+// Flow is the money in and out of the checking account in one month.
+// Amounts are in cents; Expenses are negative.
+type Flow struct {
+	Month    time.Time `json:"month"`
+	Income   int64     `json:"income"`
+	Expenses int64     `json:"expenses"`
+}
+
+// Net is what was left of the income: income plus (negative) expenses.
+func (f Flow) Net() int64 {
+	return f.Income + f.Expenses
+}
+
+// Spending is every summary of the transactions at once, as shown by the
+// web report page.
+type Spending struct {
+	Months     []Month `json:"months"`
+	Cards      []Total `json:"cards"`
+	Categories []Total `json:"categories"`
+	Payees     []Total `json:"payees"` // the top ones, see ByPayee
+	Cashflow   []Flow  `json:"cashflow,omitempty"`
+}
+
+// Summarize builds every summary, with the top payees by spending. Like
+// Cashflow, the totals leave out transfers, and projected installments too.
+func Summarize(transactions []parser.Transaction, top int) (Spending, error) {
+	var (
+		s   Spending
+		err error
+	)
+
+	if s.Months, err = Monthly(transactions); err != nil {
+		return Spending{}, err
+	}
+
+	if s.Cards, err = ByCard(transactions); err != nil {
+		return Spending{}, err
+	}
+
+	if s.Categories, err = ByCategory(transactions); err != nil {
+		return Spending{}, err
+	}
+
+	if s.Payees, err = ByPayee(transactions, top); err != nil {
+		return Spending{}, err
+	}
+
+	if s.Cashflow, err = Cashflow(transactions); err != nil {
+		return Spending{}, err
+	}
+
+	return s, nil
+}
+
+// ByCard sums the transactions by card ending, the ones without a card
+// (account statements, screenshots) under an empty name.
+func ByCard(transactions []parser.Transaction) ([]Total, error) {
+	return totals(transactions, func(t parser.Transaction) (string, string) {
+		return t.Card, t.Card
+	})
+}
+
+// ByCategory sums the transactions by category: the invoice's, or the one
+// suggested by the statement kind.
+func ByCategory(transactions []parser.Transaction) ([]Total, error) {
+	return totals(transactions, func(t parser.Transaction) (string, string) {
+		category := cmp.Or(t.Category, t.Kind.Category())
+
+		return category, category
+	})
+}
+
+// ByPayee sums the transactions by payee, compared as parser.NormalizePayee
+// does and named as first seen, keeping the top ones by spending: all of
+// them when top is 0.
+func ByPayee(transactions []parser.Transaction, top int) ([]Total, error) {
+	result, err := totals(transactions, func(t parser.Transaction) (string, string) {
+		return parser.NormalizePayee(t.Payee), t.Payee
+	})
+
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+
+	return result, err
+}
+
+// totals groups the counted transactions by the key returned by group along
+// with the name of the group, sorted by spending and then by name.
+func totals(transactions []parser.Transaction, group func(parser.Transaction) (key, name string)) ([]Total, error) {
+	var (
+		result []Total
+		index  = make(map[string]int)
+	)
+
+	for _, t := range transactions {
+		if !counted(t) {
+			continue
+		}
+
+		cents, err := parser.ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		key, name := group(t)
+
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, Total{Name: name})
+		}
+
+		result[i].Count++
+
+		if cents < 0 {
+			result[i].Debits += cents
+		} else {
+			result[i].Credits += cents
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b Total) int {
+		return cmp.Or(cmp.Compare(a.Debits, b.Debits), cmp.Compare(a.Name, b.Name))
+	})
+
+	return result, nil
+}
+
+// Cashflow sums the income and expenses of the account statement by month,
+// in chronological order. Transfers to the card and money moved to or from
+// investments stay in the family, so they're left out.
+func Cashflow(transactions []parser.Transaction) ([]Flow, error) {
+	var (
+		result []Flow
+		index  = make(map[time.Time]int)
+	)
+
+	for _, t := range transactions {
+		if t.Source != parser.SourceStatement || t.Transfer != "" ||
+			t.Kind == parser.KindInvestment || t.Kind == parser.KindRedemption {
+			continue
+		}
+
+		cents, err := parser.ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		month := MonthOf(t.Date)
+
+		i, ok := index[month]
+		if !ok {
+			i = len(result)
+			index[month] = i
+			result = append(result, Flow{Month: month})
+		}
+
+		if cents < 0 {
+			result[i].Expenses += cents
+		} else {
+			result[i].Income += cents
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Flow) int {
+		return a.Month.Compare(b.Month)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpending(t *testing.T) {
+	t.Parallel()
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.Local) }
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	transactions := []parser.Transaction{
+		{Date: day(1, 5), Payee: "AMAZON BR", Amount: "-200,00", Card: "1234", Category: "Compras"},
+		{Date: day(1, 9), Payee: "Amazon BR", Amount: "-50,00", Card: "5678", Category: "Compras"},
+		{Date: day(1, 12), Payee: "PADARIA", Amount: "-20,00", Card: "1234", Category: "Alimentação"},
+		{Date: day(1, 20), Payee: "ESTORNO PADARIA", Amount: "20,00", Card: "1234", Category: "Alimentação"},
+		{Date: day(1, 5), Payee: "SALARIO", Amount: "5.000,00", Kind: parser.KindSalary, Source: parser.SourceStatement},
+		{Date: day(1, 10), Payee: "TARIFA", Amount: "-30,00", Kind: parser.KindFee, Source: parser.SourceStatement},
+		{Date: day(1, 11), Payee: "APLICACAO CDB", Amount: "-1.000,00", Kind: parser.KindInvestment, Source: parser.SourceStatement},
+		{Date: day(2, 10), Payee: "PAGAMENTO FATURA", Amount: "-250,00", Transfer: "C6 1234", Source: parser.SourceStatement},
+		{Date: day(2, 10), Payee: "PAGAMENTO RECEBIDO", Amount: "250,00", Card: "1234", Transfer: "C6 Conta Corrente"},
+		{Date: day(2, 5), Payee: "PIX RECEBIDO", Amount: "100,00", Kind: parser.KindPixReceived, Source: parser.SourceStatement},
+		{Date: day(2, 5), Payee: "AMAZON BR", Amount: "-200,00", Card: "1234", Category: "Compras", Installment: true, Future: true},
+	}
+
+	spending, err := report.Summarize(transactions, 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, []report.Total{
+		{Name: "", Count: 4, Debits: -103000, Credits: 510000},
+		{Name: "1234", Count: 3, Debits: -22000, Credits: 2000},
+		{Name: "5678", Count: 1, Debits: -5000},
+	}, spending.Cards)
+
+	assert.Equal(t, []report.Total{
+		{Name: "Aplicação", Count: 1, Debits: -100000},
+		{Name: "Compras", Count: 2, Debits: -25000},
+		{Name: "Tarifas", Count: 1, Debits: -3000},
+		{Name: "Alimentação", Count: 2, Debits: -2000, Credits: 2000},
+		{Name: "PIX recebido", Count: 1, Credits: 10000},
+		{Name: "Salário", Count: 1, Credits: 500000},
+	}, spending.Categories)
+
+	assert.Equal(t, []report.Total{
+		{Name: "APLICACAO CDB", Count: 1, Debits: -100000},
+		{Name: "AMAZON BR", Count: 2, Debits: -25000},
+	}, spending.Payees)
+
+	assert.Equal(t, []report.Flow{
+		{Month: month(1), Income: 500000, Expenses: -3000},
+		{Month: month(2), Income: 10000},
+	}, spending.Cashflow)
+	assert.Equal(t, int64(497000), spending.Cashflow[0].Net())
+
+	// the invoice payment and the projected installment count nowhere
+	require.Len(t, spending.Months, 2)
+	assert.Equal(t, 1, spending.Months[1].Count)
+	assert.Equal(t, int64(130000), spending.Months[1].Change)
+
+	payees, err := report.ByPayee(transactions, 0)
+	require.NoError(t, err)
+	assert.Len(t, payees, 7)
+
+	_, err = report.Summarize([]parser.Transaction{{Amount: "x"}}, 0)
+	assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+}
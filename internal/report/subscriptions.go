@@ -0,0 +1,229 @@
+package report
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+)
+
+// Cadence is how often a subscription is charged.
+type Cadence string
+
+const (
+	CadenceWeekly  Cadence = "weekly"
+	CadenceMonthly Cadence = "monthly"
+	CadenceYearly  Cadence = "yearly"
+)
+
+// SubscriptionStatus tells whether a subscription is still being charged.
+type SubscriptionStatus string
+
+const (
+	SubscriptionActive SubscriptionStatus = "active"
+	// started after the first transactions analyzed
+	SubscriptionNew SubscriptionStatus = "new"
+	// not charged when expected: cancelled, or the charge is missing from the
+	// files
+	SubscriptionMissed SubscriptionStatus = "missed"
+)
+
+// maxPriceChange is how much, in percent, a charge may differ from the one
+// before for both to be the same subscription.
+const maxPriceChange = 30
+
+// cadences are tried in order: the days between charges, how many days
+// early or late a charge may come and how many charges make a subscription.
+var cadences = []struct {
+	cadence    Cadence
+	days       int
+	slack      int
+	minCharges int
+}{
+	{CadenceWeekly, 7, 1, 3},
+	{CadenceMonthly, 30, 4, 3},
+	{CadenceYearly, 365, 10, 2},
+}
+
+// PriceChange is a charge of a different amount than the one before.
+type PriceChange struct {
+	Date time.Time `json:"date"`
+	From int64     `json:"from"`
+	To   int64     `json:"to"`
+}
+
+// Subscription is a payee charged at a regular cadence. Amounts are in
+// cents, positive.
+type Subscription struct {
+	Payee        string             `json:"payee"`
+	Card         string             `json:"card,omitempty"`
+	Cadence      Cadence            `json:"cadence"`
+	Amount       int64              `json:"amount"` // the last charge
+	Charges      int                `json:"charges"`
+	First        time.Time          `json:"first"`
+	Last         time.Time          `json:"last"`
+	Next         time.Time          `json:"next"` // when the next charge is expected
+	Status       SubscriptionStatus `json:"status"`
+	PriceChanges []PriceChange      `json:"price_changes,omitempty"`
+}
+
+type charge struct {
+	date    time.Time
+	cents   int64
+	foreign string
+	card    string
+	payee   string
+}
+
+// Subscriptions finds the payees charged at a regular cadence (weekly,
+// monthly or yearly) with similar amounts across the transactions, usually
+// several merged invoices. Payees are compared as parser.NormalizePayee
+// does; installments, projected charges, transfers and invoice payments are
+// left out. International charges of the same US$ value count as the same
+// price. The status is told from the dates of the transactions: the last
+// one is today and the first one is when the analysis starts. Subscriptions
+// are sorted by payee.
+func Subscriptions(transactions []parser.Transaction) ([]Subscription, error) {
+	var (
+		start, end time.Time
+		payees     = make(map[string][]charge)
+	)
+
+	for _, t := range transactions {
+		if t.Future {
+			continue
+		}
+
+		if start.IsZero() || t.Date.Before(start) {
+			start = t.Date
+		}
+
+		if t.Date.After(end) {
+			end = t.Date
+		}
+
+		if t.Installment || t.Transfer != "" || t.Kind == parser.KindInvoicePayment || t.Kind == parser.KindInvestment {
+			continue
+		}
+
+		cents, err := parser.ParseAmount(t.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		if cents = -cents; cents <= 0 {
+			continue
+		}
+
+		key := parser.NormalizePayee(t.Payee)
+		payees[key] = append(payees[key], charge{t.Date, cents, t.ForeignAmount, t.Card, t.Payee})
+	}
+
+	var subscriptions []Subscription
+
+	for _, charges := range payees {
+		slices.SortStableFunc(charges, func(a, b charge) int {
+			return a.date.Compare(b.date)
+		})
+
+		if s, ok := subscription(charges, start, end); ok {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+
+	slices.SortFunc(subscriptions, func(a, b Subscription) int {
+		return cmp.Or(cmp.Compare(a.Payee, b.Payee), a.First.Compare(b.First))
+	})
+
+	return subscriptions, nil
+}
+
+// subscription tells whether the charges of a payee, sorted by date, make a
+// subscription: every charge a cadence after the one before and at most
+// half of them changing the price.
+func subscription(charges []charge, start, end time.Time) (Subscription, bool) {
+	for _, c := range cadences {
+		if len(charges) < c.minCharges || !regular(charges, c.days, c.slack) {
+			continue
+		}
+
+		var changes []PriceChange
+
+		for i := 1; i < len(charges); i++ {
+			prev, cur := charges[i-1], charges[i]
+			if prev.cents == cur.cents || (cur.foreign != "" && cur.foreign == prev.foreign) {
+				continue
+			}
+
+			if abs(cur.cents-prev.cents)*100 > maxPriceChange*max(cur.cents, prev.cents) {
+				return Subscription{}, false
+			}
+
+			changes = append(changes, PriceChange{Date: cur.date, From: prev.cents, To: cur.cents})
+		}
+
+		if len(changes)*2 > len(charges)-1 {
+			return Subscription{}, false
+		}
+
+		first, last := charges[0], charges[len(charges)-1]
+		s := Subscription{
+			Payee:        last.payee,
+			Card:         last.card,
+			Cadence:      c.cadence,
+			Amount:       last.cents,
+			Charges:      len(charges),
+			First:        first.date,
+			Last:         last.date,
+			Next:         next(last.date, c.cadence),
+			Status:       SubscriptionActive,
+			PriceChanges: changes,
+		}
+
+		switch slack := 24 * time.Hour * time.Duration(c.slack); {
+		case end.Sub(s.Next) > slack:
+			s.Status = SubscriptionMissed
+		case s.First.Sub(next(start, c.cadence)) > slack:
+			s.Status = SubscriptionNew
+		}
+
+		return s, true
+	}
+
+	return Subscription{}, false
+}
+
+// regular tells whether every charge comes days after the one before, give
+// or take slack days.
+func regular(charges []charge, days, slack int) bool {
+	for i := 1; i < len(charges); i++ {
+		d := int(math.Round(charges[i].date.Sub(charges[i-1].date).Hours() / 24))
+		if d < days-slack || d > days+slack {
+			return false
+		}
+	}
+
+	return true
+}
+
+// next is when the charge after one on date is expected.
+func next(date time.Time, cadence Cadence) time.Time {
+	switch cadence {
+	case CadenceWeekly:
+		return date.AddDate(0, 0, 7)
+	case CadenceYearly:
+		return date.AddDate(1, 0, 0)
+	default:
+		return date.AddDate(0, 1, 0)
+	}
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,100 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"git.home/c6bank-transactions/internal/parser"
+	"git.home/c6bank-transactions/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	tx := func(date time.Time, payee, amount string) parser.Transaction {
+		return parser.Transaction{Date: date, Payee: payee, Amount: amount, Card: "1234", Source: parser.SourceInvoice}
+	}
+
+	transactions := []parser.Transaction{
+		tx(day(1, 5), "NETFLIX.COM", "-39,90"),
+		tx(day(2, 5), "NETFLIX.COM", "-39,90"),
+		tx(day(3, 6), "NETFLIX.COM", "-44,90"),
+		tx(day(4, 5), "Netflix.com", "-44,90"),
+		// stopped after March
+		tx(day(1, 10), "SMARTFIT", "-119,90"),
+		tx(day(2, 10), "SMARTFIT", "-119,90"),
+		tx(day(3, 10), "SMARTFIT", "-119,90"),
+		// started in February
+		tx(day(2, 20), "SPOTIFY", "-21,90"),
+		tx(day(3, 20), "SPOTIFY", "-21,90"),
+		tx(day(4, 20), "SPOTIFY", "-21,90"),
+		// irregular and varying
+		tx(day(1, 3), "MERCADO", "-250,00"),
+		tx(day(1, 30), "MERCADO", "-80,00"),
+		tx(day(3, 2), "MERCADO", "-310,00"),
+		tx(day(4, 1), "MERCADO", "-95,00"),
+		// installments and projected charges aren't subscriptions
+		{Date: day(1, 15), Payee: "LOJA", Amount: "-100,00", Memo: "1/4", Installment: true},
+		{Date: day(2, 15), Payee: "LOJA", Amount: "-100,00", Memo: "2/4", Installment: true},
+		{Date: day(3, 15), Payee: "LOJA", Amount: "-100,00", Memo: "3/4", Installment: true},
+		{Date: day(5, 15), Payee: "LOJA", Amount: "-100,00", Memo: "4/4", Installment: true, Future: true},
+		// monthly salary is income
+		tx(day(1, 30), "SALARIO", "5.000,00"),
+		tx(day(3, 2), "SALARIO", "5.000,00"),
+		tx(day(3, 30), "SALARIO", "5.000,00"),
+		tx(day(4, 30), "PADARIA", "-12,00"),
+	}
+
+	subscriptions, err := report.Subscriptions(transactions)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 3)
+
+	assert.Equal(t, report.Subscription{
+		Payee:        "Netflix.com",
+		Card:         "1234",
+		Cadence:      report.CadenceMonthly,
+		Amount:       4490,
+		Charges:      4,
+		First:        day(1, 5),
+		Last:         day(4, 5),
+		Next:         day(5, 5),
+		Status:       report.SubscriptionActive,
+		PriceChanges: []report.PriceChange{{Date: day(3, 6), From: 3990, To: 4490}},
+	}, subscriptions[0])
+
+	assert.Equal(t, "SMARTFIT", subscriptions[1].Payee)
+	assert.Equal(t, report.SubscriptionMissed, subscriptions[1].Status)
+	assert.Equal(t, day(4, 10), subscriptions[1].Next)
+
+	assert.Equal(t, "SPOTIFY", subscriptions[2].Payee)
+	assert.Equal(t, report.SubscriptionNew, subscriptions[2].Status)
+	assert.Empty(t, subscriptions[2].PriceChanges)
+}
+
+func TestSubscriptions_Yearly(t *testing.T) {
+	t.Parallel()
+
+	transactions := []parser.Transaction{
+		{Date: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Payee: "ANUIDADE APP", Amount: "-99,00"},
+		{Date: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Payee: "ANUIDADE APP", Amount: "-99,00"},
+		{Date: time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC), Payee: "JORNAL", Amount: "-19,90", Source: parser.SourceScreenshot},
+		{Date: time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC), Payee: "JORNAL", Amount: "-19,90", Source: parser.SourceScreenshot},
+		{Date: time.Date(2026, 1, 21, 0, 0, 0, 0, time.UTC), Payee: "JORNAL", Amount: "-19,90", Source: parser.SourceScreenshot},
+	}
+
+	subscriptions, err := report.Subscriptions(transactions)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+
+	assert.Equal(t, report.CadenceYearly, subscriptions[0].Cadence)
+	assert.Equal(t, report.SubscriptionActive, subscriptions[0].Status)
+	assert.Equal(t, report.CadenceWeekly, subscriptions[1].Cadence)
+	// the last transaction is the yearly one, weeks after the last issue
+	assert.Equal(t, report.SubscriptionMissed, subscriptions[1].Status)
+
+	_, err = report.Subscriptions([]parser.Transaction{{Amount: "x"}})
+	assert.ErrorIs(t, err, parser.ErrInvalidAmount)
+}